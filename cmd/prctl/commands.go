@@ -0,0 +1,480 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"Backend-trainee-assignment-autumn-2025/pkg/client"
+)
+
+func (a *app) dispatch(ctx context.Context, args []string) error {
+	cmd, rest := args[0], args[1:]
+	sub := ""
+	if len(rest) > 0 {
+		sub = rest[0]
+	}
+
+	// Exports and imports stream bodies of any size, so only the other
+	// commands get a deadline.
+	if cmd != "export" && cmd != "import" && !(cmd == "pr" && sub == "import") && a.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.timeout)
+		defer cancel()
+	}
+
+	switch {
+	case cmd == "team" && sub == "add":
+		return a.teamAdd(ctx, rest[1:])
+	case cmd == "team" && sub == "get":
+		return a.teamGet(ctx, rest[1:])
+	case cmd == "team" && sub == "fairness":
+		return a.teamFairness(ctx, rest[1:])
+	case cmd == "team" && sub == "rebalance":
+		return a.teamRebalance(ctx, rest[1:])
+	case cmd == "team" && sub == "deactivate":
+		return a.teamDeactivate(ctx, rest[1:])
+	case cmd == "user" && (sub == "activate" || sub == "deactivate"):
+		return a.userSetActive(ctx, rest[1:], sub == "activate")
+	case cmd == "pr" && sub == "create":
+		return a.prCreate(ctx, rest[1:])
+	case cmd == "pr" && sub == "merge":
+		return a.prMerge(ctx, rest[1:])
+	case cmd == "pr" && sub == "reassign":
+		return a.prReassign(ctx, rest[1:])
+	case cmd == "pr" && sub == "list":
+		return a.prList(ctx, rest[1:])
+	case cmd == "pr" && sub == "import":
+		return a.prImport(ctx, rest[1:])
+	case cmd == "reviews":
+		return a.reviews(ctx, rest)
+	case cmd == "stats":
+		return a.stats(ctx, rest)
+	case cmd == "export" && (sub == "users" || sub == "prs" || sub == "reviewers"):
+		return a.export(ctx, sub, rest[1:])
+	case cmd == "export" && sub == "snapshot":
+		return a.exportSnapshot(ctx, rest[1:])
+	case cmd == "import" && sub == "snapshot":
+		return a.importSnapshot(ctx, rest[1:])
+	}
+	return usageError("unknown command " + strings.Join(args, " ") + "; run prctl -h")
+}
+
+// memberFlags collects repeated -member ID:USERNAME[:inactive] values.
+type memberFlags []client.TeamMember
+
+func (m *memberFlags) String() string { return "" }
+
+func (m *memberFlags) Set(v string) error {
+	parts := strings.Split(v, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("want ID:USERNAME[:inactive], got %q", v)
+	}
+	member := client.TeamMember{UserID: parts[0], Username: parts[1], IsActive: true}
+	if len(parts) == 3 {
+		if parts[2] != "inactive" {
+			return fmt.Errorf("unknown member flag %q", parts[2])
+		}
+		member.IsActive = false
+	}
+	*m = append(*m, member)
+	return nil
+}
+
+func (a *app) teamAdd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("team add", flag.ContinueOnError)
+	name := fs.String("name", "", "team name")
+	file := fs.String("f", "", "JSON file with the team, - for stdin")
+	var members memberFlags
+	fs.Var(&members, "member", "member as ID:USERNAME[:inactive], repeatable")
+	if err := fs.Parse(args); err != nil {
+		return usageError("team add -name TEAM -member ID:USERNAME ... | -f FILE")
+	}
+
+	var t client.Team
+	switch {
+	case *file != "":
+		if err := readJSON(*file, &t); err != nil {
+			return err
+		}
+	case *name != "" && len(members) > 0:
+		t = client.Team{TeamName: *name, Members: members}
+	default:
+		return usageError("team add -name TEAM -member ID:USERNAME ... | -f FILE")
+	}
+
+	team, err := a.c.AddTeam(ctx, t)
+	if err != nil {
+		return err
+	}
+	return a.out.team(team)
+}
+
+func (a *app) teamGet(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return usageError("team get TEAM")
+	}
+	team, err := a.c.GetTeam(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return a.out.team(team)
+}
+
+func (a *app) teamFairness(ctx context.Context, args []string) error {
+	usage := "team fairness TEAM [-from TIME] [-to TIME]"
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return usageError(usage)
+	}
+	fs := flag.NewFlagSet("team fairness", flag.ContinueOnError)
+	from := fs.String("from", "", "from this time, RFC3339")
+	to := fs.String("to", "", "before this time, RFC3339")
+	if err := fs.Parse(args[1:]); err != nil || fs.NArg() > 0 {
+		return usageError(usage)
+	}
+	fromT, toT, err := parseRange(*from, *to)
+	if err != nil {
+		return err
+	}
+
+	r, err := a.c.Fairness(ctx, args[0], fromT, toT)
+	if err != nil {
+		return err
+	}
+	return a.out.fairness(r)
+}
+
+func (a *app) teamRebalance(ctx context.Context, args []string) error {
+	usage := "team rebalance TEAM [-max N]"
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return usageError(usage)
+	}
+	fs := flag.NewFlagSet("team rebalance", flag.ContinueOnError)
+	maxMoves := fs.Int("max", 0, "at most this many moves (default: the server's limit)")
+	if err := fs.Parse(args[1:]); err != nil || fs.NArg() > 0 {
+		return usageError(usage)
+	}
+
+	r, err := a.c.Rebalance(ctx, args[0], *maxMoves)
+	if err != nil {
+		return err
+	}
+	return a.out.rebalance(r)
+}
+
+func (a *app) teamDeactivate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("team deactivate", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "only show what would change")
+	if err := fs.Parse(args); err != nil || fs.NArg() < 2 {
+		return usageError("team deactivate [-dry-run] TEAM USER_ID ...")
+	}
+	deactivate := a.c.DeactivateUsers
+	if *dryRun {
+		deactivate = a.c.DeactivateUsersDryRun
+	}
+	r, err := deactivate(ctx, fs.Arg(0), fs.Args()[1:])
+	if err != nil {
+		return err
+	}
+	return a.out.deactivation(r)
+}
+
+func (a *app) userSetActive(ctx context.Context, args []string, active bool) error {
+	if len(args) != 1 {
+		return usageError("user activate|deactivate USER_ID")
+	}
+	u, err := a.c.SetUserActive(ctx, args[0], active)
+	if err != nil {
+		return err
+	}
+	return a.out.user(u)
+}
+
+func (a *app) prCreate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("pr create", flag.ContinueOnError)
+	var req client.CreatePRRequest
+	fs.StringVar(&req.PullRequestID, "id", "", "pull request ID")
+	fs.StringVar(&req.PullRequestName, "name", "", "pull request name")
+	fs.StringVar(&req.AuthorID, "author", "", "author user ID")
+	if err := fs.Parse(args); err != nil || req.PullRequestID == "" || req.PullRequestName == "" || req.AuthorID == "" {
+		return usageError("pr create -id ID -name NAME -author USER_ID")
+	}
+	pr, err := a.c.CreatePR(ctx, req)
+	if err != nil {
+		return err
+	}
+	return a.out.pr(pr)
+}
+
+func (a *app) prMerge(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return usageError("pr merge PR_ID")
+	}
+	pr, err := a.c.MergePR(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return a.out.pr(pr)
+}
+
+func (a *app) prReassign(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("pr reassign", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "only show the replacement, change nothing")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 2 {
+		return usageError("pr reassign [-dry-run] PR_ID OLD_USER_ID")
+	}
+	reassign := a.c.ReassignReviewer
+	if *dryRun {
+		reassign = a.c.ReassignReviewerDryRun
+	}
+	res, err := reassign(ctx, fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	return a.out.reassign(res)
+}
+
+func (a *app) prList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("pr list", flag.ContinueOnError)
+	f := prFilterFlags(fs)
+	author := fs.String("author", "", "author user ID")
+	team := fs.String("team", "", "author's team")
+	if err := fs.Parse(args); err != nil {
+		return usageError("pr list [-status S] [-stale B] [-author ID] [-team TEAM]")
+	}
+	filter, err := f()
+	if err != nil {
+		return err
+	}
+	filter.AuthorID, filter.TeamName = *author, *team
+
+	prs, err := a.c.ListPRs(ctx, filter)
+	if err != nil {
+		return err
+	}
+	return a.out.prList(prs)
+}
+
+func (a *app) prImport(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return usageError("pr import FILE")
+	}
+	var r io.Reader = os.Stdin
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var results []client.BatchPRResult
+	failed := 0
+	err := a.c.ImportPRs(ctx, r, func(res client.BatchPRResult) error {
+		results = append(results, res)
+		if res.Error != nil {
+			failed++
+		}
+		return nil
+	})
+	if perr := a.out.importResults(results); perr != nil {
+		return perr
+	}
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d pull requests not imported", failed, len(results))
+	}
+	return nil
+}
+
+func (a *app) reviews(ctx context.Context, args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return usageError("reviews USER_ID [-status S] [-stale B]")
+	}
+	fs := flag.NewFlagSet("reviews", flag.ContinueOnError)
+	f := prFilterFlags(fs)
+	if err := fs.Parse(args[1:]); err != nil {
+		return usageError("reviews USER_ID [-status S] [-stale B]")
+	}
+	filter, err := f()
+	if err != nil {
+		return err
+	}
+
+	prs, err := a.c.GetReview(ctx, args[0], filter)
+	if err != nil {
+		return err
+	}
+	return a.out.prList(prs)
+}
+
+func (a *app) stats(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	var f client.StatsFilter
+	fs.StringVar(&f.TeamName, "team", "", "only this team (the reviewer's team for assignments, the author's for PRs)")
+	from := fs.String("from", "", "from this time, RFC3339")
+	to := fs.String("to", "", "before this time, RFC3339")
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		return usageError("stats [-team TEAM] [-from TIME] [-to TIME]")
+	}
+	var err error
+	if f.From, f.To, err = parseRange(*from, *to); err != nil {
+		return err
+	}
+
+	s, err := a.c.Stats(ctx, f)
+	if err != nil {
+		return err
+	}
+	return a.out.stats(s)
+}
+
+func (a *app) export(ctx context.Context, what string, args []string) error {
+	fs := flag.NewFlagSet("export "+what, flag.ContinueOnError)
+	var f client.ExportFilter
+	fs.StringVar(&f.TeamName, "team", "", "only this team (the author's team for PRs)")
+	fs.StringVar(&f.Format, "format", "ndjson", "ndjson or csv")
+	from := fs.String("from", "", "from this time, RFC3339 (PRs and reviewers)")
+	to := fs.String("to", "", "before this time, RFC3339 (PRs and reviewers)")
+	out := fs.String("out", "-", "output file, - for stdout")
+	usage := "export users|prs|reviewers [-team TEAM] [-from TIME] [-to TIME] [-format ndjson|csv] [-out FILE]"
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		return usageError(usage)
+	}
+	var err error
+	if f.From, f.To, err = parseRange(*from, *to); err != nil {
+		return err
+	}
+
+	var body io.ReadCloser
+	switch what {
+	case "users":
+		body, err = a.c.ExportUsers(ctx, f)
+	case "prs":
+		body, err = a.c.ExportPRs(ctx, f)
+	case "reviewers":
+		body, err = a.c.ExportReviewers(ctx, f)
+	}
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return writeOut(*out, body)
+}
+
+func (a *app) exportSnapshot(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("export snapshot", flag.ContinueOnError)
+	out := fs.String("out", "-", "output file, - for stdout")
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		return usageError("export snapshot [-out FILE]")
+	}
+	body, err := a.c.Snapshot(ctx)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return writeOut(*out, body)
+}
+
+func (a *app) importSnapshot(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("import snapshot", flag.ContinueOnError)
+	mode := fs.String("mode", "fail", "existing rows: fail, skip or overwrite")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return usageError("import snapshot [-mode fail|skip|overwrite] FILE")
+	}
+	var r io.Reader = os.Stdin
+	if path := fs.Arg(0); path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	res, err := a.c.RestoreSnapshot(ctx, r, *mode)
+	var ce *client.Error
+	if errors.As(err, &ce) {
+		for _, d := range ce.Details {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", d.Field, d.Reason)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return a.out.restore(res)
+}
+
+// writeOut copies r to the file at path, or to stdout for "-".
+func writeOut(path string, r io.Reader) error {
+	var w io.Writer = os.Stdout
+	if path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	_, err := io.Copy(w, r)
+	return err
+}
+
+// parseRange parses the -from and -to flags; empty ones stay zero.
+func parseRange(from, to string) (time.Time, time.Time, error) {
+	var times [2]time.Time
+	for i, v := range []string{from, to} {
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return times[0], times[1], usageError("-from and -to take RFC3339 times, e.g. 2025-10-01T00:00:00Z")
+		}
+		times[i] = t
+	}
+	return times[0], times[1], nil
+}
+
+// prFilterFlags registers -status and -stale on fs and returns a function
+// building the filter after parsing.
+func prFilterFlags(fs *flag.FlagSet) func() (client.PRFilter, error) {
+	status := fs.String("status", "", "OPEN, MERGED or CLOSED")
+	stale := fs.String("stale", "", "true or false")
+	return func() (client.PRFilter, error) {
+		f := client.PRFilter{Status: strings.ToUpper(*status)}
+		if *stale != "" {
+			b, err := strconv.ParseBool(*stale)
+			if err != nil {
+				return f, usageError("-stale must be true or false")
+			}
+			f.Stale = &b
+		}
+		return f, nil
+	}
+}
+
+func readJSON(path string, v interface{}) error {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
@@ -0,0 +1,110 @@
+// Command prctl administers the PR reviewer service through its HTTP API.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"Backend-trainee-assignment-autumn-2025/pkg/client"
+)
+
+const usage = `usage: prctl [global flags] COMMAND [args]
+
+commands:
+  team add -name TEAM -member ID:USERNAME[:inactive] ...   create or update a team
+  team add -f FILE                                          same, from a JSON file ("-" for stdin)
+  team get TEAM
+  team fairness TEAM [-from TIME] [-to TIME]                how evenly reviews are spread
+  team rebalance TEAM [-max N]                              move open reviews to the least loaded
+  team deactivate [-dry-run] TEAM USER_ID ...               deactivate members, show how reviewers changed
+  user activate USER_ID
+  user deactivate USER_ID
+  pr create -id ID -name NAME -author USER_ID
+  pr merge PR_ID
+  pr reassign [-dry-run] PR_ID OLD_USER_ID
+  pr list [-status OPEN|MERGED|CLOSED] [-stale true|false] [-author ID] [-team TEAM]
+  pr import FILE                                            create PRs from NDJSON, one per line ("-" for stdin)
+  reviews USER_ID [-status ...] [-stale ...]                review queue of a user
+  stats [-team TEAM] [-from TIME] [-to TIME]                assignments and review times, per user and team
+  export users|prs|reviewers [-team TEAM] [-from TIME] [-to TIME] [-format ndjson|csv] [-out FILE]
+  export snapshot [-out FILE]                               JSON archive of all data
+  import snapshot [-mode fail|skip|overwrite] FILE          restore an archive ("-" for stdin)
+
+global flags:`
+
+type app struct {
+	c   *client.Client
+	out printer
+	// timeout bounds commands that do not stream data.
+	timeout time.Duration
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	fs := flag.NewFlagSet("prctl", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, usage)
+		fs.PrintDefaults()
+	}
+	baseURL := fs.String("url", envOr("PRCTL_URL", "http://localhost:8080"), "API base URL (PRCTL_URL)")
+	apiKey := fs.String("api-key", os.Getenv("PRCTL_API_KEY"), "API key (PRCTL_API_KEY)")
+	token := fs.String("token", os.Getenv("PRCTL_TOKEN"), "JWT bearer token (PRCTL_TOKEN)")
+	output := fs.String("o", "table", "output format: table or json")
+	timeout := fs.Duration("timeout", 30*time.Second, "request timeout; exports and imports run without one")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	out, err := newPrinter(*output, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	var opts []client.Option
+	if *apiKey != "" {
+		opts = append(opts, client.WithAPIKey(*apiKey))
+	}
+	if *token != "" {
+		opts = append(opts, client.WithBearerToken(*token))
+	}
+	a := &app{c: client.New(*baseURL, opts...), out: out, timeout: *timeout}
+
+	err = a.dispatch(context.Background(), fs.Args())
+	var ue usageError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &ue):
+		fmt.Fprintln(os.Stderr, ue.Error())
+		return 2
+	default:
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+}
+
+// usageError reports a malformed command line.
+type usageError string
+
+func (e usageError) Error() string {
+	return "usage: prctl " + string(e)
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"Backend-trainee-assignment-autumn-2025/pkg/client"
+)
+
+// printer renders command results as aligned tables or as JSON.
+type printer struct {
+	w    io.Writer
+	json bool
+}
+
+func newPrinter(format string, w io.Writer) (printer, error) {
+	switch format {
+	case "table":
+		return printer{w: w}, nil
+	case "json":
+		return printer{w: w, json: true}, nil
+	}
+	return printer{}, fmt.Errorf("unknown output format %q, want table or json", format)
+}
+
+func (p printer) encode(v interface{}) error {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// table writes a header and rows separated by tabs.
+func (p printer) table(header string, rows [][]string) error {
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, header)
+	for _, r := range rows {
+		fmt.Fprintln(tw, strings.Join(r, "\t"))
+	}
+	return tw.Flush()
+}
+
+func (p printer) team(t client.Team) error {
+	if p.json {
+		return p.encode(t)
+	}
+	rows := make([][]string, 0, len(t.Members))
+	for _, m := range t.Members {
+		rows = append(rows, []string{t.TeamName, m.UserID, m.Username, active(m.IsActive)})
+	}
+	return p.table("TEAM\tUSER_ID\tUSERNAME\tSTATUS", rows)
+}
+
+func (p printer) fairness(r client.FairnessReport) error {
+	if p.json {
+		return p.encode(r)
+	}
+	ratio := "-"
+	if r.MaxMinRatio != nil {
+		ratio = fmt.Sprintf("%.2f", *r.MaxMinRatio)
+	}
+	fmt.Fprintf(p.w, "team %s: %d assignments over %d active members, mean %.2f, stddev %.2f, gini %.3f, max/min %s\n\n",
+		r.TeamName, r.TotalAssignments, r.ActiveMembers, r.Mean, r.StdDev, r.Gini, ratio)
+
+	load := map[string]string{}
+	for _, m := range r.Overloaded {
+		load[m.UserID] = "over"
+	}
+	for _, m := range r.Underloaded {
+		load[m.UserID] = "under"
+	}
+	rows := make([][]string, 0, len(r.Members))
+	for _, m := range r.Members {
+		rows = append(rows, []string{m.UserID, m.Username, fmt.Sprint(m.Assignments), fmt.Sprint(m.OpenAssignments), load[m.UserID]})
+	}
+	return p.table("USER_ID\tUSERNAME\tASSIGNMENTS\tOPEN\tLOAD", rows)
+}
+
+func (p printer) rebalance(r client.RebalanceResult) error {
+	if p.json {
+		return p.encode(r)
+	}
+	rows := make([][]string, 0, len(r.Moves))
+	for _, m := range r.Moves {
+		rows = append(rows, []string{m.PullRequestID, m.FromUserID, m.ToUserID})
+	}
+	return p.table("PR_ID\tFROM\tTO", rows)
+}
+
+func (p printer) deactivation(r client.DeactivationResult) error {
+	if p.json {
+		return p.encode(r)
+	}
+	rows := make([][]string, 0, len(r.PullRequests))
+	for _, c := range r.PullRequests {
+		added := c.AddedReviewer
+		switch {
+		case c.LeftWithoutReviewers:
+			added = "NONE LEFT"
+		case added == "":
+			added = "-"
+		}
+		rows = append(rows, []string{c.PullRequestID, list(c.RemovedReviewers), added})
+	}
+	if err := p.table("DEACTIVATED\tNOT_FOUND\tOTHER_TEAM\tALREADY_INACTIVE", [][]string{{
+		list(r.Deactivated), list(r.NotFound), list(r.OtherTeam), list(r.AlreadyInactive)}}); err != nil {
+		return err
+	}
+	fmt.Fprintln(p.w)
+	return p.table("PR_ID\tREMOVED\tADDED", rows)
+}
+
+func (p printer) user(u client.User) error {
+	if p.json {
+		return p.encode(u)
+	}
+	return p.table("USER_ID\tUSERNAME\tTEAM\tSTATUS",
+		[][]string{{u.UserID, u.Username, u.TeamName, active(u.IsActive)}})
+}
+
+func (p printer) pr(pr client.PullRequest) error {
+	if p.json {
+		return p.encode(pr)
+	}
+	return p.table("PR_ID\tNAME\tAUTHOR\tSTATUS\tREVIEWERS",
+		[][]string{{pr.PullRequestID, pr.PullRequestName, pr.AuthorID, status(pr.Status, pr.IsStale), list(pr.AssignedReviewers)}})
+}
+
+func (p printer) reassign(r client.ReassignResult) error {
+	if p.json {
+		return p.encode(r)
+	}
+	return p.table("PR_ID\tREPLACED_BY\tREVIEWERS",
+		[][]string{{r.PR.PullRequestID, r.ReplacedBy, list(r.PR.AssignedReviewers)}})
+}
+
+func (p printer) prList(prs []client.PullRequestShort) error {
+	if p.json {
+		return p.encode(prs)
+	}
+	rows := make([][]string, 0, len(prs))
+	for _, pr := range prs {
+		rows = append(rows, []string{pr.PullRequestID, pr.PullRequestName, pr.AuthorID, status(pr.Status, pr.IsStale)})
+	}
+	return p.table("PR_ID\tNAME\tAUTHOR\tSTATUS", rows)
+}
+
+func (p printer) importResults(results []client.BatchPRResult) error {
+	if p.json {
+		return p.encode(results)
+	}
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		var result string
+		switch {
+		case r.Error != nil:
+			result = r.Error.Code + ": " + r.Error.Message
+		case r.PR != nil:
+			result = "created, reviewers " + list(r.PR.AssignedReviewers)
+		}
+		rows = append(rows, []string{fmt.Sprint(r.Line), r.PullRequestID, result})
+	}
+	return p.table("LINE\tPR_ID\tRESULT", rows)
+}
+
+func (p printer) restore(r client.RestoreResult) error {
+	if p.json {
+		return p.encode(r)
+	}
+	rows := make([][]string, 0, 5)
+	for _, k := range []struct {
+		name string
+		c    client.RestoreCounts
+	}{
+		{"teams", r.Teams}, {"users", r.Users}, {"pull_requests", r.PullRequests},
+		{"reviewers", r.Reviewers}, {"history", r.History},
+	} {
+		rows = append(rows, []string{k.name, fmt.Sprint(k.c.Created), fmt.Sprint(k.c.Updated), fmt.Sprint(k.c.Skipped)})
+	}
+	return p.table("KIND\tCREATED\tUPDATED\tSKIPPED", rows)
+}
+
+func (p printer) stats(s client.Stats) error {
+	if p.json {
+		return p.encode(s)
+	}
+	rows := make([][]string, 0, len(s.Users))
+	for _, u := range s.Users {
+		rows = append(rows, []string{u.TeamName, u.UserID, active(u.IsActive),
+			fmt.Sprint(u.OpenAssignments), fmt.Sprint(u.TotalAssignments)})
+	}
+	if err := p.table("TEAM\tREVIEWER\tSTATUS\tOPEN\tTOTAL", rows); err != nil {
+		return err
+	}
+	fmt.Fprintln(p.w)
+
+	rows = make([][]string, 0, len(s.Teams)+1)
+	for _, t := range s.Teams {
+		rows = append(rows, []string{t.TeamName, fmt.Sprint(t.ActiveMembers),
+			fmt.Sprint(t.OpenAssignments), fmt.Sprint(t.TotalAssignments),
+			fmt.Sprint(t.PullRequests), fmt.Sprint(t.Merged),
+			seconds(t.TimeToMerge.MedianSeconds), seconds(t.TimeToMerge.P90Seconds),
+			seconds(t.TimeToFirstReview.MedianSeconds), seconds(t.TimeToFirstReview.P90Seconds)})
+	}
+	rows = append(rows, []string{"(all)", "", "", "", "", fmt.Sprint(s.TimeToMerge.Count),
+		seconds(s.TimeToMerge.MedianSeconds), seconds(s.TimeToMerge.P90Seconds),
+		seconds(s.TimeToFirstReview.MedianSeconds), seconds(s.TimeToFirstReview.P90Seconds)})
+	return p.table("TEAM\tACTIVE\tOPEN\tTOTAL\tPRS\tMERGED\tMERGE_P50\tMERGE_P90\tFIRST_REVIEW_P50\tFIRST_REVIEW_P90", rows)
+}
+
+// seconds renders an optional number of seconds as a duration.
+func seconds(s *float64) string {
+	if s == nil {
+		return "-"
+	}
+	return time.Duration(*s * float64(time.Second)).Round(time.Second).String()
+}
+
+func active(b bool) string {
+	if b {
+		return "active"
+	}
+	return "inactive"
+}
+
+func status(s string, stale bool) string {
+	if stale {
+		return s + " (stale)"
+	}
+	return s
+}
+
+func list(ids []string) string {
+	if len(ids) == 0 {
+		return "-"
+	}
+	return strings.Join(ids, ",")
+}
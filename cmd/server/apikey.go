@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"Backend-trainee-assignment-autumn-2025/internal/auth"
+	"Backend-trainee-assignment-autumn-2025/internal/storage"
+)
+
+const apikeyUsage = `usage:
+  server apikey create -name NAME -scopes read,write:prs,admin:teams,admin:keys
+  server apikey list
+  server apikey revoke ID`
+
+// runAPIKey implements the "apikey" subcommand used to bootstrap and manage
+// API keys directly against the database.
+func runAPIKey(ctx context.Context, store *storage.Store, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, apikeyUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("apikey create", flag.ContinueOnError)
+		name := fs.String("name", "", "key name")
+		scopes := fs.String("scopes", "", "comma-separated scopes")
+		if err := fs.Parse(args[1:]); err != nil {
+			return 2
+		}
+		sc, err := auth.ParseScopes(*scopes)
+		if *name == "" || err != nil {
+			fmt.Fprintln(os.Stderr, apikeyUsage)
+			return 2
+		}
+
+		key, hash, err := auth.GenerateKey()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		k, err := store.CreateAPIKey(ctx, *name, sc, hash)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("id:     %s\nscopes: %s\nkey:    %s\n", k.ID, strings.Join(k.Scopes, ","), key)
+		fmt.Fprintln(os.Stderr, "store the key now, it cannot be shown again")
+		return 0
+
+	case "list":
+		keys, err := store.ListAPIKeys(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tNAME\tSCOPES\tCREATED\tREVOKED")
+		for _, k := range keys {
+			revoked := "-"
+			if k.RevokedAt != nil {
+				revoked = k.RevokedAt.UTC().Format(time.RFC3339)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+				k.ID, k.Name, strings.Join(k.Scopes, ","), k.CreatedAt.UTC().Format(time.RFC3339), revoked)
+		}
+		_ = tw.Flush()
+		return 0
+
+	case "revoke":
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, apikeyUsage)
+			return 2
+		}
+		if err := store.RevokeAPIKey(ctx, args[1]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Println("revoked", args[1])
+		return 0
+	}
+
+	fmt.Fprintln(os.Stderr, apikeyUsage)
+	return 2
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"Backend-trainee-assignment-autumn-2025/internal/config"
+)
+
+const configUsage = `usage:
+  server config print [-format yaml|toml] [-config FILE] [-section.setting VALUE ...]`
+
+// runConfig implements the "config" subcommand. "config print" writes the
+// effective configuration, with secrets redacted, and fails if it is
+// invalid.
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, configUsage)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("config print", flag.ContinueOnError)
+	format := fs.String("format", "yaml", "output format: yaml or toml")
+	cfg, err := config.Load(fs, args[1:], os.Getenv)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if err := cfg.Redacted().Write(os.Stdout, *format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid config:\n%v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -2,65 +2,253 @@ package main
 
 import (
 	"context"
-	"log"
+	"flag"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"Backend-trainee-assignment-autumn-2025/internal/auth"
+	"Backend-trainee-assignment-autumn-2025/internal/config"
 	"Backend-trainee-assignment-autumn-2025/internal/handlers"
+	"Backend-trainee-assignment-autumn-2025/internal/jobs"
+	"Backend-trainee-assignment-autumn-2025/internal/logging"
+	"Backend-trainee-assignment-autumn-2025/internal/metrics"
+	"Backend-trainee-assignment-autumn-2025/internal/notify"
+	"Backend-trainee-assignment-autumn-2025/internal/openapi"
+	"Backend-trainee-assignment-autumn-2025/internal/sla"
 	"Backend-trainee-assignment-autumn-2025/internal/storage"
+	"Backend-trainee-assignment-autumn-2025/internal/tracing"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func main() {
-	dsn := os.Getenv("DATABASE_URL")
-	if dsn == "" {
-		log.Fatal("DATABASE_URL environment variable is required")
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "config" {
+		os.Exit(runConfig(args[1:]))
 	}
 
-	cfg, err := pgxpool.ParseConfig(dsn)
+	// The apikey subcommand has its own flags; settings then come only from
+	// the config file and environment.
+	var apikeyArgs []string
+	if len(args) > 0 && args[0] == "apikey" {
+		apikeyArgs, args = args[1:], nil
+	}
+
+	cfg, err := config.Load(flag.NewFlagSet("server", flag.ExitOnError), args, os.Getenv)
 	if err != nil {
-		log.Fatalf("parse dsn: %v", err)
+		fatal("load config", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		fatal("invalid config", err)
 	}
 
-	cfg.MaxConns = 10
-	cfg.MaxConnLifetime = 5 * time.Minute
+	level, _ := logging.ParseLevel(cfg.Log.Level)
+	slog.SetDefault(logging.New(os.Stdout, level))
 
-	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		ServiceName: cfg.Tracing.ServiceName,
+		Endpoint:    cfg.Tracing.Endpoint,
+	})
 	if err != nil {
-		log.Fatalf("open pool: %v", err)
+		fatal("tracing", err)
 	}
+	defer func() {
+		_ = shutdownTracing(context.Background())
+	}()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	pool, err := openPool(cfg.Database)
+	if err != nil {
+		fatal("open database", err)
+	}
+	defer pool.Close()
 
-	for {
-		err = pool.Ping(ctx)
-		if err == nil {
-			break
+	store := storage.NewStore(pool, storage.WithReviewersPerPR(cfg.Assignment.ReviewersPerPR))
+	metrics.RegisterPool(pool)
+	metrics.RegisterOpenReviews(store)
+
+	if cfg.Database.Migrate {
+		if err := store.Migrate(context.Background()); err != nil {
+			fatal("migrate database", err)
 		}
-		log.Printf("waiting for db: %v", err)
-		time.Sleep(time.Second)
 	}
 
-	store := storage.NewStore(pool)
+	if apikeyArgs != nil {
+		ctx := auth.WithPrincipal(context.Background(), auth.System("cli"))
+		code := runAPIKey(ctx, store, apikeyArgs)
+		pool.Close()
+		os.Exit(code)
+	}
 
-	mux := http.NewServeMux()
-	handlers.RegisterHandlers(mux, store)
+	// runCtx is cancelled on SIGINT/SIGTERM; background jobs stop with it and
+	// /readyz starts failing so the instance is taken out of rotation.
+	runCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	notifier := newNotifier(cfg.Notifier, store)
+	if notifier != nil {
+		at, _ := jobs.ParseTimeOfDay(cfg.Notifier.DigestAt)
+		go jobs.RunDaily(runCtx, at, "digest", jobs.NewDigest(store, notifier).Run)
 	}
 
+	cal, _ := sla.ParseCalendar(cfg.SLA.Workday)
+	monitor := sla.NewMonitor(store, notifier, cal)
+	go jobs.RunEvery(runCtx, cfg.SLA.CheckInterval, "sla", monitor.Run)
+
+	if cfg.Stale.AfterDays > 0 {
+		day := 24 * time.Hour
+		stale := jobs.NewStale(store, notifier,
+			time.Duration(cfg.Stale.AfterDays)*day, time.Duration(cfg.Stale.CloseAfterDays)*day)
+		go jobs.RunEvery(runCtx, cfg.Stale.CheckInterval, "stale", stale.Run)
+	}
+
+	go jobs.RunEvery(runCtx, time.Hour, "idempotency", jobs.PurgeIdempotencyKeys(store))
+
+	var contractOpts []openapi.Option
+	if cfg.Server.ValidateResponses {
+		contractOpts = append(contractOpts, openapi.WithResponseValidation())
+	}
+	contract, err := openapi.New(contractOpts...)
+	if err != nil {
+		fatal("openapi", err)
+	}
+
+	opts := []handlers.Option{
+		handlers.WithValidator(contract),
+		handlers.WithNotifier(notifier),
+		handlers.WithSLAMonitor(monitor),
+		handlers.WithShutdown(runCtx),
+		handlers.WithIdempotencyTTL(cfg.Server.IdempotencyTTL),
+	}
+	if cfg.Auth.Enabled {
+		authenticator, err := newAuthenticator(runCtx, cfg.Auth, store)
+		if err != nil {
+			fatal("jwt", err)
+		}
+		opts = append(opts, handlers.WithAuthenticator(authenticator))
+	} else {
+		slog.Warn("auth.enabled is false, API is unauthenticated")
+	}
+
+	mux := http.NewServeMux()
+	handlers.RegisterHandlers(mux, store, opts...)
+
 	srv := &http.Server{
-		Addr:         ":" + port,
-		Handler:      mux,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 15 * time.Second,
+		Addr:         ":" + cfg.Server.Port,
+		Handler:      logging.Middleware(mux),
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
 	}
 
-	log.Printf("listening on %s", srv.Addr)
-	log.Fatal(srv.ListenAndServe())
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("listening", "addr", srv.Addr)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		fatal("serve", err)
+	case <-runCtx.Done():
+	}
+	stop()
+
+	// Keep serving while load balancers notice the failing /readyz.
+	slog.Info("shutting down", "delay", cfg.Server.ShutdownDelay, "timeout", cfg.Server.ShutdownTimeout)
+	time.Sleep(cfg.Server.ShutdownDelay)
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancelShutdown()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("shutdown: requests still in flight", "error", err)
+	}
+	slog.Info("stopped")
+}
+
+// openPool connects to the database, waiting up to ConnectTimeout for it to
+// come up.
+func openPool(c config.DatabaseConfig) (*pgxpool.Pool, error) {
+	pcfg, err := pgxpool.ParseConfig(c.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parse url: %w", err)
+	}
+	pcfg.MaxConns = c.MaxConns
+	pcfg.MinConns = c.MinConns
+	pcfg.MaxConnLifetime = c.MaxConnLifetime
+	pcfg.MaxConnIdleTime = c.MaxConnIdleTime
+	pcfg.ConnConfig.Tracer = tracing.QueryTracer{}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), pcfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.ConnectTimeout)
+	defer cancel()
+	for {
+		err := pool.Ping(ctx)
+		if err == nil {
+			return pool, nil
+		}
+		if ctx.Err() != nil {
+			pool.Close()
+			return nil, fmt.Errorf("database not reachable after %s: %w", c.ConnectTimeout, err)
+		}
+		slog.Info("waiting for db", "error", err)
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func newNotifier(c config.NotifierConfig, store *storage.Store) *notify.Notifier {
+	if c.SMTP.Host == "" {
+		slog.Info("notifier.smtp.host not set, email notifications disabled")
+		return nil
+	}
+
+	smtp := notify.NewSMTP(notify.SMTPConfig{
+		Host:     c.SMTP.Host,
+		Port:     c.SMTP.Port,
+		From:     c.SMTP.From,
+		Username: c.SMTP.Username,
+		Password: c.SMTP.Password,
+		StartTLS: c.SMTP.StartTLS,
+	})
+	return notify.New(smtp, store)
+}
+
+func newAuthenticator(ctx context.Context, c config.AuthConfig, store *storage.Store) (*auth.Authenticator, error) {
+	var verifier *auth.JWTVerifier
+	if c.JWKSFile != "" || c.JWKSURL != "" {
+		var err error
+		verifier, err = auth.NewJWTVerifier(ctx, auth.JWTConfig{
+			JWKSFile:    c.JWKSFile,
+			JWKSURL:     c.JWKSURL,
+			Issuer:      c.Issuer,
+			Audience:    c.Audience,
+			UserIDClaim: c.UserIDClaim,
+			RolesClaim:  c.RolesClaim,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return auth.NewAuthenticator(store, verifier), nil
+}
+
+// fatal logs msg with err and exits.
+func fatal(msg string, err error) {
+	if err != nil {
+		slog.Error(msg, "error", err)
+	} else {
+		slog.Error(msg)
+	}
+	os.Exit(1)
 }
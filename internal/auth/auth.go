@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+)
+
+const (
+	ScopeRead       = "read"
+	ScopeWritePRs   = "write:prs"
+	ScopeAdminTeams = "admin:teams"
+	ScopeAdminKeys  = "admin:keys"
+)
+
+var AllScopes = []string{ScopeRead, ScopeWritePRs, ScopeAdminTeams, ScopeAdminKeys}
+
+const (
+	RoleAdmin    = "admin"
+	RoleTeamLead = "team_lead"
+)
+
+var (
+	ErrNoCredentials = errors.New("no credentials")
+	ErrInvalidKey    = errors.New("invalid api key")
+	ErrInvalidScope  = errors.New("invalid scope")
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// Principal is the authenticated caller of a request. UserID and Roles are
+// set only for bearer tokens; API keys act as service accounts.
+type Principal struct {
+	Subject string
+	UserID  string
+	Roles   []string
+	Scopes  []string
+}
+
+func (p Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope reports whether p was granted scope.
+func (p Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	// Any write or admin scope also grants read.
+	return scope == ScopeRead && len(p.Scopes) > 0
+}
+
+// System returns the principal recorded for work the server does on its
+// own, such as background jobs and CLI commands.
+func System(name string) Principal {
+	return Principal{Subject: "system:" + name}
+}
+
+type ctxKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(ctxKey{}).(Principal)
+	return p, ok
+}
+
+func ValidScope(s string) bool {
+	for _, v := range AllScopes {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+type Store interface {
+	// LookupAPIKey returns the active (not revoked) key with the given hash.
+	LookupAPIKey(ctx context.Context, hash string) (models.APIKey, bool, error)
+	TouchAPIKey(ctx context.Context, id string) error
+	// GetUserRoles returns the roles granted to a user in the database.
+	GetUserRoles(ctx context.Context, userID string) ([]string, error)
+}
+
+type Authenticator struct {
+	store Store
+	jwt   *JWTVerifier
+}
+
+// NewAuthenticator accepts API keys looked up in store and bearer tokens
+// verified by jwt. A nil jwt disables bearer tokens.
+func NewAuthenticator(store Store, jwt *JWTVerifier) *Authenticator {
+	return &Authenticator{store: store, jwt: jwt}
+}
+
+// Authenticate resolves the caller of r from its credentials.
+func (a *Authenticator) Authenticate(r *http.Request) (Principal, error) {
+	if h := r.Header.Get("Authorization"); h != "" {
+		scheme, token, _ := strings.Cut(h, " ")
+		if !strings.EqualFold(scheme, "Bearer") || a.jwt == nil {
+			return Principal{}, ErrInvalidToken
+		}
+		p, err := a.jwt.Verify(r.Context(), strings.TrimSpace(token))
+		if err != nil {
+			return p, err
+		}
+		return a.withStoredRoles(r.Context(), p)
+	}
+
+	key := strings.TrimSpace(r.Header.Get(APIKeyHeader))
+	if key == "" {
+		return Principal{}, ErrNoCredentials
+	}
+
+	k, ok, err := a.store.LookupAPIKey(r.Context(), HashKey(key))
+	if err != nil {
+		return Principal{}, err
+	}
+	if !ok {
+		return Principal{}, ErrInvalidKey
+	}
+	_ = a.store.TouchAPIKey(r.Context(), k.ID)
+
+	return Principal{Subject: "key:" + k.ID, Scopes: k.Scopes}, nil
+}
+
+// withStoredRoles adds the roles granted in the database to those carried by
+// the token and recomputes the scopes.
+func (a *Authenticator) withStoredRoles(ctx context.Context, p Principal) (Principal, error) {
+	stored, err := a.store.GetUserRoles(ctx, p.UserID)
+	if err != nil {
+		return p, err
+	}
+	for _, r := range stored {
+		if !p.HasRole(r) {
+			p.Roles = append(p.Roles, r)
+		}
+	}
+	p.Scopes = scopesForRoles(p.Roles)
+	return p, nil
+}
+
+// GenerateKey returns a new random API key and the hash to store for it.
+func GenerateKey() (key, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	key = "prk_" + base64.RawURLEncoding.EncodeToString(b)
+	return key, HashKey(key), nil
+}
+
+// HashKey hashes an API key for storage. Keys carry 256 bits of entropy, so
+// a plain SHA-256 is enough and keeps lookups indexable.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseScopes splits a comma-separated scope list and validates it.
+func ParseScopes(s string) ([]string, error) {
+	var res []string
+	for _, sc := range strings.Split(s, ",") {
+		sc = strings.TrimSpace(sc)
+		if sc == "" {
+			continue
+		}
+		if !ValidScope(sc) {
+			return nil, ErrInvalidScope
+		}
+		res = append(res, sc)
+	}
+	if len(res) == 0 {
+		return nil, ErrInvalidScope
+	}
+	return res, nil
+}
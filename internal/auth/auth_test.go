@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+)
+
+type memKeys map[string]models.APIKey
+
+func (m memKeys) LookupAPIKey(_ context.Context, hash string) (models.APIKey, bool, error) {
+	k, ok := m[hash]
+	return k, ok, nil
+}
+
+func (m memKeys) TouchAPIKey(context.Context, string) error { return nil }
+
+func (m memKeys) GetUserRoles(context.Context, string) ([]string, error) { return nil, nil }
+
+func TestAuthenticate(t *testing.T) {
+	key, hash, err := GenerateKey()
+	require.NoError(t, err)
+
+	a := NewAuthenticator(memKeys{hash: {ID: "k1", Scopes: []string{ScopeWritePRs}}}, nil)
+
+	r := httptest.NewRequest("GET", "/team/get", nil)
+	_, err = a.Authenticate(r)
+	require.ErrorIs(t, err, ErrNoCredentials)
+
+	r.Header.Set(APIKeyHeader, "prk_wrong")
+	_, err = a.Authenticate(r)
+	require.ErrorIs(t, err, ErrInvalidKey)
+
+	r.Header.Set(APIKeyHeader, key)
+	p, err := a.Authenticate(r)
+	require.NoError(t, err)
+	require.Equal(t, "key:k1", p.Subject)
+	require.True(t, p.HasScope(ScopeWritePRs))
+	require.True(t, p.HasScope(ScopeRead))
+	require.False(t, p.HasScope(ScopeAdminTeams))
+}
+
+func TestHasScopeEmpty(t *testing.T) {
+	require.False(t, Principal{}.HasScope(ScopeRead))
+}
+
+func TestParseScopes(t *testing.T) {
+	sc, err := ParseScopes("read, write:prs")
+	require.NoError(t, err)
+	require.Equal(t, []string{ScopeRead, ScopeWritePRs}, sc)
+
+	_, err = ParseScopes("read,root")
+	require.ErrorIs(t, err, ErrInvalidScope)
+
+	_, err = ParseScopes("")
+	require.ErrorIs(t, err, ErrInvalidScope)
+}
@@ -0,0 +1,225 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidToken = errors.New("invalid bearer token")
+
+type JWTConfig struct {
+	// Exactly one of JWKSFile and JWKSURL should be set.
+	JWKSFile string
+	JWKSURL  string
+	Issuer   string
+	Audience string
+	// UserIDClaim and RolesClaim name the claims holding the user_id and
+	// the role list. Dotted paths such as "realm_access.roles" are allowed.
+	UserIDClaim string
+	RolesClaim  string
+	// CacheTTL is how long keys fetched from JWKSURL are trusted.
+	CacheTTL time.Duration
+}
+
+// JWTVerifier validates bearer tokens against a JWKS.
+type JWTVerifier struct {
+	cfg    JWTConfig
+	client *http.Client
+
+	mu        sync.RWMutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+func NewJWTVerifier(ctx context.Context, cfg JWTConfig) (*JWTVerifier, error) {
+	if (cfg.JWKSFile == "") == (cfg.JWKSURL == "") {
+		return nil, errors.New("jwt: set exactly one of JWKS file or URL")
+	}
+	if cfg.UserIDClaim == "" {
+		cfg.UserIDClaim = "sub"
+	}
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = "roles"
+	}
+	if cfg.CacheTTL == 0 {
+		cfg.CacheTTL = 10 * time.Minute
+	}
+
+	v := &JWTVerifier{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}}
+	if err := v.refresh(ctx); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (v *JWTVerifier) refresh(ctx context.Context) error {
+	var data []byte
+	var err error
+	if v.cfg.JWKSFile != "" {
+		data, err = os.ReadFile(v.cfg.JWKSFile)
+	} else {
+		data, err = v.fetch(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("load jwks: %w", err)
+	}
+
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.fetchedAt = time.Now()
+	v.mu.Unlock()
+	return nil
+}
+
+func (v *JWTVerifier) fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.cfg.JWKSURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", v.cfg.JWKSURL, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// key returns the verification key for kid. Keys served from a URL are
+// refetched when stale or when an unknown kid shows up after a rotation.
+func (v *JWTVerifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.RLock()
+	k, ok := v.lookup(kid)
+	age := time.Since(v.fetchedAt)
+	v.mu.RUnlock()
+
+	remote := v.cfg.JWKSURL != ""
+	if remote && (age > v.cfg.CacheTTL || (!ok && age > 30*time.Second)) {
+		if err := v.refresh(ctx); err != nil {
+			if !ok {
+				return nil, err
+			}
+		} else {
+			v.mu.RLock()
+			k, ok = v.lookup(kid)
+			v.mu.RUnlock()
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return k, nil
+}
+
+func (v *JWTVerifier) lookup(kid string) (crypto.PublicKey, bool) {
+	if k, ok := v.keys[kid]; ok {
+		return k, true
+	}
+	// Tokens without a kid are accepted only when the set is unambiguous.
+	if kid == "" && len(v.keys) == 1 {
+		for _, k := range v.keys {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
+// Verify checks the token signature and standard claims and maps the token
+// to a Principal.
+func (v *JWTVerifier) Verify(ctx context.Context, raw string) (Principal, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if v.cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.cfg.Issuer))
+	}
+	if v.cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(v.cfg.Audience))
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.NewParser(opts...).ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.key(ctx, kid)
+	})
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	userID, _ := claimPath(claims, v.cfg.UserIDClaim).(string)
+	if userID == "" {
+		return Principal{}, fmt.Errorf("%w: claim %q missing", ErrInvalidToken, v.cfg.UserIDClaim)
+	}
+	roles := stringList(claimPath(claims, v.cfg.RolesClaim))
+
+	return Principal{
+		Subject: "user:" + userID,
+		UserID:  userID,
+		Roles:   roles,
+		Scopes:  scopesForRoles(roles),
+	}, nil
+}
+
+func claimPath(claims jwt.MapClaims, path string) interface{} {
+	var cur interface{} = map[string]interface{}(claims)
+	for _, part := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		cur = m[part]
+	}
+	return cur
+}
+
+func stringList(v interface{}) []string {
+	switch t := v.(type) {
+	case string:
+		return strings.FieldsFunc(t, func(r rune) bool { return r == ' ' || r == ',' })
+	case []interface{}:
+		res := make([]string, 0, len(t))
+		for _, e := range t {
+			if s, ok := e.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+	return nil
+}
+
+// scopesForRoles maps user roles onto API key scopes. Admins get everything.
+// Team leads also reach the team endpoints, where the role policy narrows
+// them to their own team; other users read, work on PRs and change their own
+// settings.
+func scopesForRoles(roles []string) []string {
+	scopes := []string{ScopeRead, ScopeWritePRs}
+	for _, r := range roles {
+		switch r {
+		case RoleAdmin:
+			return AllScopes
+		case RoleTeamLead:
+			scopes = []string{ScopeRead, ScopeWritePRs, ScopeAdminTeams}
+		}
+	}
+	return scopes
+}
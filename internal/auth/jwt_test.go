@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func b64(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+func rsaJWK(kid string, k *rsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA", "kid": kid, "use": "sig",
+		"n": b64(k.N.Bytes()), "e": b64(big.NewInt(int64(k.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, k *ecdsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "EC", "kid": kid, "crv": "P-256",
+		"x": b64(k.X.FillBytes(make([]byte, 32))), "y": b64(k.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func writeJWKS(t *testing.T, keys ...map[string]string) string {
+	data, err := json.Marshal(map[string]interface{}{"keys": keys})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	tok := jwt.NewWithClaims(method, claims)
+	tok.Header["kid"] = kid
+	s, err := tok.SignedString(key)
+	require.NoError(t, err)
+	return s
+}
+
+func TestJWTVerifierFile(t *testing.T) {
+	rk, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ek, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	v, err := NewJWTVerifier(context.Background(), JWTConfig{
+		JWKSFile:   writeJWKS(t, rsaJWK("rsa1", rk), ecJWK("ec1", ek)),
+		Issuer:     "https://idp.example.com",
+		Audience:   "pr-reviewer",
+		RolesClaim: "realm_access.roles",
+	})
+	require.NoError(t, err)
+
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":          "u1",
+			"iss":          "https://idp.example.com",
+			"aud":          "pr-reviewer",
+			"exp":          time.Now().Add(time.Hour).Unix(),
+			"realm_access": map[string]interface{}{"roles": []string{"admin"}},
+		}
+	}
+
+	p, err := v.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, "rsa1", rk, valid()))
+	require.NoError(t, err)
+	require.Equal(t, "u1", p.UserID)
+	require.True(t, p.HasRole(RoleAdmin))
+	require.True(t, p.HasScope(ScopeAdminTeams))
+
+	c := valid()
+	delete(c, "realm_access")
+	p, err = v.Verify(context.Background(), sign(t, jwt.SigningMethodES256, "ec1", ek, c))
+	require.NoError(t, err)
+	require.False(t, p.HasScope(ScopeAdminKeys))
+	require.False(t, p.HasScope(ScopeAdminTeams))
+	require.True(t, p.HasScope(ScopeWritePRs))
+
+	bad := map[string]func(jwt.MapClaims){
+		"expired":      func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"no exp":       func(c jwt.MapClaims) { delete(c, "exp") },
+		"wrong issuer": func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" },
+		"wrong aud":    func(c jwt.MapClaims) { c["aud"] = "other" },
+		"no subject":   func(c jwt.MapClaims) { delete(c, "sub") },
+	}
+	for name, mutate := range bad {
+		t.Run(name, func(t *testing.T) {
+			c := valid()
+			mutate(c)
+			_, err := v.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, "rsa1", rk, c))
+			require.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, err = v.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, "rsa1", other, valid()))
+	require.ErrorIs(t, err, ErrInvalidToken, "bad signature")
+
+	_, err = v.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, "nope", rk, valid()))
+	require.ErrorIs(t, err, ErrInvalidToken, "unknown kid")
+
+	_, err = v.Verify(context.Background(), sign(t, jwt.SigningMethodHS256, "rsa1", []byte("secret"), valid()))
+	require.ErrorIs(t, err, ErrInvalidToken, "symmetric algorithm")
+}
+
+func TestJWTVerifierURL(t *testing.T) {
+	rk, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []interface{}{rsaJWK("k", rk)}})
+	}))
+	defer srv.Close()
+
+	v, err := NewJWTVerifier(context.Background(), JWTConfig{JWKSURL: srv.URL})
+	require.NoError(t, err)
+
+	tok := sign(t, jwt.SigningMethodRS256, "k", rk, jwt.MapClaims{
+		"sub": "u2", "roles": "dev lead", "exp": time.Now().Add(time.Minute).Unix(),
+	})
+
+	a := NewAuthenticator(memKeys{}, v)
+	r := httptest.NewRequest("POST", "/pullRequest/merge", nil)
+	r.Header.Set("Authorization", "Bearer "+tok)
+	p, err := a.Authenticate(r)
+	require.NoError(t, err)
+	require.Equal(t, "u2", p.UserID)
+	require.Equal(t, []string{"dev", "lead"}, p.Roles)
+
+	r.Header.Set("Authorization", "Basic dTpw")
+	_, err = a.Authenticate(r)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestScopesForRoles(t *testing.T) {
+	require.Equal(t, []string{ScopeRead, ScopeWritePRs}, scopesForRoles(nil))
+	require.Equal(t, []string{ScopeRead, ScopeWritePRs, ScopeAdminTeams}, scopesForRoles([]string{"dev", RoleTeamLead}))
+	require.Equal(t, AllScopes, scopesForRoles([]string{RoleTeamLead, RoleAdmin}))
+}
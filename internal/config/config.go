@@ -0,0 +1,235 @@
+// Package config loads the server configuration. Values are layered:
+// built-in defaults, then an optional YAML or TOML file, then environment
+// variables, then command-line flags.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"Backend-trainee-assignment-autumn-2025/internal/jobs"
+	"Backend-trainee-assignment-autumn-2025/internal/logging"
+	"Backend-trainee-assignment-autumn-2025/internal/sla"
+	"Backend-trainee-assignment-autumn-2025/internal/tracing"
+)
+
+// Struct tags: yaml/toml name the key in the file (and, joined with dots,
+// the flag), env names the overriding environment variable and secret marks
+// values that Redacted hides.
+type Config struct {
+	Server     ServerConfig     `yaml:"server" toml:"server"`
+	Database   DatabaseConfig   `yaml:"database" toml:"database"`
+	Assignment AssignmentConfig `yaml:"assignment" toml:"assignment"`
+	SLA        SLAConfig        `yaml:"sla" toml:"sla"`
+	Stale      StaleConfig      `yaml:"stale" toml:"stale"`
+	Notifier   NotifierConfig   `yaml:"notifier" toml:"notifier"`
+	Auth       AuthConfig       `yaml:"auth" toml:"auth"`
+	Log        LogConfig        `yaml:"log" toml:"log"`
+	Tracing    TracingConfig    `yaml:"tracing" toml:"tracing"`
+}
+
+type ServerConfig struct {
+	Port            string        `yaml:"port" toml:"port" env:"PORT"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" toml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" toml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	ShutdownDelay   time.Duration `yaml:"shutdown_delay" toml:"shutdown_delay" env:"SHUTDOWN_DELAY"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	// ValidateResponses checks responses against openapi.yml too; for
+	// test stands, as it buffers every response.
+	ValidateResponses bool `yaml:"validate_responses" toml:"validate_responses" env:"OPENAPI_VALIDATE_RESPONSES"`
+	// IdempotencyTTL is how long responses to requests with an
+	// Idempotency-Key are kept for replay.
+	IdempotencyTTL time.Duration `yaml:"idempotency_ttl" toml:"idempotency_ttl" env:"IDEMPOTENCY_TTL"`
+}
+
+type DatabaseConfig struct {
+	URL             string        `yaml:"url" toml:"url" env:"DATABASE_URL" secret:"url"`
+	MaxConns        int32         `yaml:"max_conns" toml:"max_conns" env:"DB_MAX_CONNS"`
+	MinConns        int32         `yaml:"min_conns" toml:"min_conns" env:"DB_MIN_CONNS"`
+	MaxConnLifetime time.Duration `yaml:"max_conn_lifetime" toml:"max_conn_lifetime" env:"DB_MAX_CONN_LIFETIME"`
+	MaxConnIdleTime time.Duration `yaml:"max_conn_idle_time" toml:"max_conn_idle_time" env:"DB_MAX_CONN_IDLE_TIME"`
+	// ConnectTimeout bounds how long startup waits for the database.
+	ConnectTimeout time.Duration `yaml:"connect_timeout" toml:"connect_timeout" env:"DB_CONNECT_TIMEOUT"`
+	// Migrate applies migrations/init.sql on startup.
+	Migrate bool `yaml:"migrate" toml:"migrate" env:"DB_MIGRATE"`
+}
+
+type AssignmentConfig struct {
+	// ReviewersPerPR is how many reviewers a new pull request gets.
+	ReviewersPerPR int `yaml:"reviewers_per_pr" toml:"reviewers_per_pr" env:"REVIEWERS_PER_PR"`
+}
+
+type SLAConfig struct {
+	// Workday is the working-hours window, "HH:MM-HH:MM".
+	Workday       string        `yaml:"workday" toml:"workday" env:"SLA_WORKDAY"`
+	CheckInterval time.Duration `yaml:"check_interval" toml:"check_interval" env:"SLA_CHECK_INTERVAL"`
+}
+
+type StaleConfig struct {
+	// AfterDays of inactivity flag a PR as stale; 0 disables the job.
+	AfterDays int `yaml:"after_days" toml:"after_days" env:"STALE_AFTER_DAYS"`
+	// CloseAfterDays after being flagged close the PR; 0 never closes.
+	CloseAfterDays int           `yaml:"close_after_days" toml:"close_after_days" env:"STALE_CLOSE_AFTER_DAYS"`
+	CheckInterval  time.Duration `yaml:"check_interval" toml:"check_interval" env:"STALE_CHECK_INTERVAL"`
+}
+
+type NotifierConfig struct {
+	SMTP SMTPConfig `yaml:"smtp" toml:"smtp"`
+	// DigestAt is the UTC time of day, "HH:MM", the daily digest is sent.
+	DigestAt string `yaml:"digest_at" toml:"digest_at" env:"DIGEST_AT"`
+}
+
+// SMTPConfig leaves notifications off while Host is empty.
+type SMTPConfig struct {
+	Host     string `yaml:"host" toml:"host" env:"SMTP_HOST"`
+	Port     int    `yaml:"port" toml:"port" env:"SMTP_PORT"`
+	From     string `yaml:"from" toml:"from" env:"SMTP_FROM"`
+	Username string `yaml:"username" toml:"username" env:"SMTP_USERNAME"`
+	Password string `yaml:"password" toml:"password" env:"SMTP_PASSWORD" secret:"true"`
+	StartTLS bool   `yaml:"starttls" toml:"starttls" env:"SMTP_STARTTLS"`
+}
+
+type AuthConfig struct {
+	Enabled     bool   `yaml:"enabled" toml:"enabled" env:"AUTH_ENABLED"`
+	JWKSFile    string `yaml:"jwks_file" toml:"jwks_file" env:"AUTH_JWKS_FILE"`
+	JWKSURL     string `yaml:"jwks_url" toml:"jwks_url" env:"AUTH_JWKS_URL"`
+	Issuer      string `yaml:"issuer" toml:"issuer" env:"AUTH_JWT_ISSUER"`
+	Audience    string `yaml:"audience" toml:"audience" env:"AUTH_JWT_AUDIENCE"`
+	UserIDClaim string `yaml:"user_id_claim" toml:"user_id_claim" env:"AUTH_JWT_USER_CLAIM"`
+	RolesClaim  string `yaml:"roles_claim" toml:"roles_claim" env:"AUTH_JWT_ROLES_CLAIM"`
+}
+
+type LogConfig struct {
+	Level string `yaml:"level" toml:"level" env:"LOG_LEVEL"`
+}
+
+type TracingConfig struct {
+	Exporter    string `yaml:"exporter" toml:"exporter" env:"OTEL_TRACES_EXPORTER"`
+	ServiceName string `yaml:"service_name" toml:"service_name" env:"OTEL_SERVICE_NAME"`
+	// Endpoint is the OTLP/HTTP collector URL, e.g. http://collector:4318.
+	Endpoint string `yaml:"endpoint" toml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+}
+
+// Default returns the configuration used when nothing overrides it.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Port:            "8080",
+			ReadTimeout:     10 * time.Second,
+			WriteTimeout:    15 * time.Second,
+			ShutdownTimeout: 15 * time.Second,
+			IdempotencyTTL:  24 * time.Hour,
+		},
+		Database: DatabaseConfig{
+			MaxConns:        10,
+			MaxConnLifetime: 5 * time.Minute,
+			MaxConnIdleTime: 30 * time.Minute,
+			ConnectTimeout:  30 * time.Second,
+			Migrate:         true,
+		},
+		Assignment: AssignmentConfig{ReviewersPerPR: 2},
+		SLA: SLAConfig{
+			Workday:       "09:00-18:00",
+			CheckInterval: 5 * time.Minute,
+		},
+		Stale: StaleConfig{CheckInterval: time.Hour},
+		Notifier: NotifierConfig{
+			SMTP:     SMTPConfig{Port: 25, From: "pr-reviewer@localhost"},
+			DigestAt: "09:00",
+		},
+		Log:     LogConfig{Level: "info"},
+		Tracing: TracingConfig{Exporter: tracing.ExporterNone},
+	}
+}
+
+// Validate reports every invalid setting at once.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	port, err := strconv.Atoi(c.Server.Port)
+	check(err == nil && port > 0 && port < 65536, "server.port: %q is not a TCP port", c.Server.Port)
+	check(c.Server.ReadTimeout > 0, "server.read_timeout must be positive")
+	check(c.Server.WriteTimeout > 0, "server.write_timeout must be positive")
+	check(c.Server.ShutdownDelay >= 0, "server.shutdown_delay must not be negative")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+	check(c.Server.IdempotencyTTL > 0, "server.idempotency_ttl must be positive")
+
+	check(c.Database.URL != "", "database.url is required (DATABASE_URL)")
+	check(c.Database.MaxConns > 0, "database.max_conns must be positive")
+	check(c.Database.MinConns >= 0 && c.Database.MinConns <= c.Database.MaxConns,
+		"database.min_conns must be between 0 and max_conns")
+	check(c.Database.MaxConnLifetime > 0, "database.max_conn_lifetime must be positive")
+	check(c.Database.MaxConnIdleTime > 0, "database.max_conn_idle_time must be positive")
+	check(c.Database.ConnectTimeout > 0, "database.connect_timeout must be positive")
+
+	check(c.Assignment.ReviewersPerPR >= 1 && c.Assignment.ReviewersPerPR <= 10,
+		"assignment.reviewers_per_pr must be between 1 and 10")
+
+	_, err = sla.ParseCalendar(c.SLA.Workday)
+	check(err == nil, "sla.workday: %v", err)
+	check(c.SLA.CheckInterval > 0, "sla.check_interval must be positive")
+
+	check(c.Stale.AfterDays >= 0, "stale.after_days must not be negative")
+	check(c.Stale.CloseAfterDays >= 0, "stale.close_after_days must not be negative")
+	check(c.Stale.CheckInterval > 0, "stale.check_interval must be positive")
+
+	check(c.Notifier.SMTP.Port > 0 && c.Notifier.SMTP.Port < 65536, "notifier.smtp.port: %d is not a TCP port", c.Notifier.SMTP.Port)
+	_, err = jobs.ParseTimeOfDay(c.Notifier.DigestAt)
+	check(err == nil, "notifier.digest_at must be HH:MM")
+
+	check(c.Auth.JWKSFile == "" || c.Auth.JWKSURL == "", "auth.jwks_file and auth.jwks_url are mutually exclusive")
+
+	_, err = logging.ParseLevel(c.Log.Level)
+	check(err == nil, "log.level: %v", err)
+
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP:
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter must be none, stdout or otlp"))
+	}
+
+	return errors.Join(errs...)
+}
+
+const redacted = "REDACTED"
+
+// Redacted returns a copy of c that is safe to print: secret fields are
+// replaced and passwords are removed from URLs.
+func (c Config) Redacted() Config {
+	_ = walk(&c, func(f field) error {
+		s, ok := f.value.Interface().(string)
+		if !ok || s == "" {
+			return nil
+		}
+		switch f.secret {
+		case "true":
+			f.value.SetString(redacted)
+		case "url":
+			f.value.SetString(redactDSN(s))
+		}
+		return nil
+	})
+	return c
+}
+
+var dsnPassword = regexp.MustCompile(`(password\s*=\s*)('(?:[^'\\]|\\.)*'|\S+)`)
+
+// redactDSN hides the password of a postgres URL or keyword/value DSN.
+func redactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		if _, has := u.User.Password(); has {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+		}
+		return u.String()
+	}
+	return dsnPassword.ReplaceAllString(dsn, "${1}"+redacted)
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func env(vars map[string]string) func(string) string {
+	return func(k string) string { return vars[k] }
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+server:
+  port: "9000"
+  read_timeout: 3s
+database:
+  url: postgres://file
+  max_conns: 20
+assignment:
+  reviewers_per_pr: 3
+`)
+
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-config", path, "-database.max_conns", "40"}, env(map[string]string{
+		"PORT":         "9100",
+		"DB_MAX_CONNS": "30",
+	}))
+	require.NoError(t, err)
+
+	require.Equal(t, "9100", cfg.Server.Port, "env overrides file")
+	require.Equal(t, 3*time.Second, cfg.Server.ReadTimeout, "file overrides default")
+	require.Equal(t, 15*time.Second, cfg.Server.WriteTimeout, "default kept")
+	require.Equal(t, "postgres://file", cfg.Database.URL)
+	require.Equal(t, int32(40), cfg.Database.MaxConns, "flag overrides env")
+	require.Equal(t, 3, cfg.Assignment.ReviewersPerPR)
+	require.NoError(t, cfg.Validate())
+}
+
+func TestLoadTOMLAndConfigFileEnv(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+[database]
+url = "postgres://toml"
+connect_timeout = "5s"
+
+[notifier.smtp]
+host = "mailpit"
+starttls = true
+`)
+
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), nil, env(map[string]string{FileEnv: path}))
+	require.NoError(t, err)
+	require.Equal(t, "postgres://toml", cfg.Database.URL)
+	require.Equal(t, 5*time.Second, cfg.Database.ConnectTimeout)
+	require.Equal(t, "mailpit", cfg.Notifier.SMTP.Host)
+	require.True(t, cfg.Notifier.SMTP.StartTLS)
+	require.Equal(t, 25, cfg.Notifier.SMTP.Port)
+}
+
+func TestLoadRejectsUnknownKeysAndBadValues(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		args []string
+		env  map[string]string
+	}{
+		{name: "unknown yaml key", file: writeFile(t, "a.yaml", "server:\n  prot: \"1\"\n")},
+		{name: "unknown toml key", file: writeFile(t, "a.toml", "[server]\nprot = \"1\"\n")},
+		{name: "bad extension", file: writeFile(t, "a.json", "{}")},
+		{name: "bad env duration", env: map[string]string{"SLA_CHECK_INTERVAL": "soon"}},
+		{name: "bad flag int", args: []string{"-database.max_conns", "many"}},
+		{name: "unknown flag", args: []string{"-nope", "1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"-config", tt.file}, args...)
+			}
+			_, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), args, env(tt.env))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := Default()
+	valid.Database.URL = "postgres://localhost/db"
+	require.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		mutate func(*Config)
+	}{
+		{"missing db url", func(c *Config) { c.Database.URL = "" }},
+		{"bad port", func(c *Config) { c.Server.Port = "http" }},
+		{"min above max conns", func(c *Config) { c.Database.MinConns = 11 }},
+		{"no reviewers", func(c *Config) { c.Assignment.ReviewersPerPR = 0 }},
+		{"bad workday", func(c *Config) { c.SLA.Workday = "9-18" }},
+		{"bad digest time", func(c *Config) { c.Notifier.DigestAt = "9am" }},
+		{"two jwks sources", func(c *Config) { c.Auth.JWKSFile, c.Auth.JWKSURL = "a", "b" }},
+		{"bad log level", func(c *Config) { c.Log.Level = "loud" }},
+		{"bad exporter", func(c *Config) { c.Tracing.Exporter = "zipkin" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid
+			tt.mutate(&c)
+			require.Error(t, c.Validate())
+		})
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	c := Default()
+	c.Database.URL = "postgres://app:s3cret@db:5432/app"
+	c.Notifier.SMTP.Password = "hunter2"
+
+	r := c.Redacted()
+	require.Equal(t, "postgres://app:REDACTED@db:5432/app", r.Database.URL)
+	require.Equal(t, "REDACTED", r.Notifier.SMTP.Password)
+	require.Equal(t, "postgres://app:s3cret@db:5432/app", c.Database.URL, "original untouched")
+
+	c.Database.URL = "host=db user=app password='s3 cret' dbname=app"
+	require.Equal(t, "host=db user=app password=REDACTED dbname=app", c.Redacted().Database.URL)
+
+	var buf bytes.Buffer
+	require.NoError(t, r.Write(&buf, "yaml"))
+	require.NotContains(t, buf.String(), "s3cret")
+	require.Contains(t, buf.String(), "max_conn_lifetime: 5m0s")
+}
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv names the environment variable holding the config file path when
+// -config is not given.
+const FileEnv = "CONFIG_FILE"
+
+// Load builds the configuration from defaults, the config file, the
+// environment and args, in increasing priority. Every leaf setting is
+// registered on fs as a flag named after its file path, e.g. -server.port or
+// -database.max_conns, next to any flags the caller defined. Load does not
+// validate; call Validate on the result.
+func Load(fs *flag.FlagSet, args []string, getenv func(string) string) (Config, error) {
+	cfg := Default()
+
+	path := fs.String("config", getenv(FileEnv), "path to a YAML or TOML config file")
+	type override struct {
+		name, value string
+	}
+	var overrides []override
+	if err := walk(&cfg, func(f field) error {
+		fs.Func(f.path, "overrides "+f.path, func(v string) error {
+			overrides = append(overrides, override{f.path, v})
+			return nil
+		})
+		return nil
+	}); err != nil {
+		return cfg, err
+	}
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+	if fs.NArg() > 0 {
+		return cfg, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	if *path != "" {
+		if err := loadFile(*path, &cfg); err != nil {
+			return cfg, err
+		}
+	}
+
+	err := walk(&cfg, func(f field) error {
+		if f.env == "" {
+			return nil
+		}
+		if v := getenv(f.env); v != "" {
+			if err := setValue(f.value, v); err != nil {
+				return fmt.Errorf("%s: %w", f.env, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return cfg, err
+	}
+
+	for _, o := range overrides {
+		err := walk(&cfg, func(f field) error {
+			if f.path != o.name {
+				return nil
+			}
+			if err := setValue(f.value, o.value); err != nil {
+				return fmt.Errorf("-%s: %w", f.path, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return cfg, err
+		}
+	}
+
+	return cfg, nil
+}
+
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(strings.NewReader(string(data)))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	case ".toml":
+		md, err := toml.Decode(string(data), cfg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("%s: unknown keys %v", path, undecoded)
+		}
+	default:
+		return fmt.Errorf("%s: config file must be .yaml, .yml or .toml", path)
+	}
+	return nil
+}
+
+// field is one leaf setting of Config.
+type field struct {
+	path   string
+	env    string
+	secret string
+	value  reflect.Value
+}
+
+// walk calls fn for every leaf field of cfg, in declaration order.
+func walk(cfg *Config, fn func(field) error) error {
+	return walkStruct(reflect.ValueOf(cfg).Elem(), "", fn)
+}
+
+func walkStruct(v reflect.Value, prefix string, fn func(field) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		path := sf.Tag.Get("yaml")
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			if err := walkStruct(fv, path, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		err := fn(field{path: path, env: sf.Tag.Get("env"), secret: sf.Tag.Get("secret"), value: fv})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func setValue(v reflect.Value, s string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+// Write encodes c as YAML or TOML.
+func (c Config) Write(w io.Writer, format string) error {
+	switch format {
+	case "yaml", "yml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(c); err != nil {
+			return err
+		}
+		return enc.Close()
+	case "toml":
+		return toml.NewEncoder(w).Encode(c)
+	}
+	return fmt.Errorf("unknown format %q, want yaml or toml", format)
+}
@@ -0,0 +1,65 @@
+// Package fairness measures how evenly reviews are spread over a team.
+package fairness
+
+import (
+	"math"
+	"sort"
+
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+)
+
+// Report computes the spread of members' Assignments: mean, population
+// standard deviation, Gini coefficient (0 for a perfectly even spread,
+// approaching 1 when one member gets everything) and the max/min ratio.
+// Members more than one standard deviation from the mean are listed as
+// over- or underloaded, most extreme first.
+func Report(members []models.MemberLoad) models.FairnessReport {
+	r := models.FairnessReport{
+		ActiveMembers: len(members),
+		Members:       members,
+		Overloaded:    []models.MemberLoad{},
+		Underloaded:   []models.MemberLoad{},
+	}
+	if r.Members == nil {
+		r.Members = []models.MemberLoad{}
+	}
+	n := len(members)
+	if n == 0 {
+		return r
+	}
+
+	counts := make([]float64, n)
+	for i, m := range members {
+		counts[i] = float64(m.Assignments)
+		r.TotalAssignments += m.Assignments
+	}
+	sort.Float64s(counts)
+	total := float64(r.TotalAssignments)
+	r.Mean = total / float64(n)
+
+	var sq, ranked float64
+	for i, c := range counts {
+		sq += (c - r.Mean) * (c - r.Mean)
+		ranked += float64(i+1) * c
+	}
+	r.StdDev = math.Sqrt(sq / float64(n))
+	if total > 0 {
+		r.Gini = 2*ranked/(float64(n)*total) - float64(n+1)/float64(n)
+	}
+	if min := counts[0]; min > 0 {
+		ratio := counts[n-1] / min
+		r.MaxMinRatio = &ratio
+	}
+
+	for _, m := range members {
+		switch c := float64(m.Assignments); {
+		case c > r.Mean+r.StdDev:
+			r.Overloaded = append(r.Overloaded, m)
+		case c < r.Mean-r.StdDev:
+			r.Underloaded = append(r.Underloaded, m)
+		}
+	}
+	sort.SliceStable(r.Overloaded, func(i, j int) bool { return r.Overloaded[i].Assignments > r.Overloaded[j].Assignments })
+	sort.SliceStable(r.Underloaded, func(i, j int) bool { return r.Underloaded[i].Assignments < r.Underloaded[j].Assignments })
+	return r
+}
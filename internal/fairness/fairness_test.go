@@ -0,0 +1,52 @@
+package fairness
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+)
+
+func loads(counts ...int) []models.MemberLoad {
+	ms := make([]models.MemberLoad, len(counts))
+	for i, c := range counts {
+		ms[i] = models.MemberLoad{UserID: string(rune('a' + i)), Assignments: c}
+	}
+	return ms
+}
+
+func TestReportEven(t *testing.T) {
+	r := Report(loads(3, 3, 3))
+	require.Equal(t, 9, r.TotalAssignments)
+	require.InDelta(t, 3, r.Mean, 1e-9)
+	require.InDelta(t, 0, r.StdDev, 1e-9)
+	require.InDelta(t, 0, r.Gini, 1e-9)
+	require.NotNil(t, r.MaxMinRatio)
+	require.InDelta(t, 1, *r.MaxMinRatio, 1e-9)
+	require.Empty(t, r.Overloaded)
+	require.Empty(t, r.Underloaded)
+}
+
+func TestReportSkewed(t *testing.T) {
+	r := Report(loads(0, 0, 0, 8))
+	require.InDelta(t, 2, r.Mean, 1e-9)
+	require.InDelta(t, 3.4641016, r.StdDev, 1e-6)
+	require.InDelta(t, 0.75, r.Gini, 1e-9)
+	require.Nil(t, r.MaxMinRatio)
+	require.Len(t, r.Overloaded, 1)
+	require.Equal(t, "d", r.Overloaded[0].UserID)
+	require.Empty(t, r.Underloaded)
+
+	r = Report(loads(1, 5, 6, 10, 6))
+	require.InDelta(t, 10, *r.MaxMinRatio, 1e-9)
+	require.Equal(t, "d", r.Overloaded[0].UserID)
+	require.Equal(t, "a", r.Underloaded[0].UserID)
+}
+
+func TestReportEmpty(t *testing.T) {
+	r := Report(nil)
+	require.Equal(t, 0, r.ActiveMembers)
+	require.NotNil(t, r.Members)
+	require.Zero(t, r.Gini)
+}
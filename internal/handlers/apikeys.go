@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"Backend-trainee-assignment-autumn-2025/internal/auth"
+	"Backend-trainee-assignment-autumn-2025/internal/storage"
+)
+
+func (s *Server) handleCreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(405)
+		return
+	}
+	var body struct {
+		Name   string   `json:"name"`
+		Scopes []string `json:"scopes"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	var fe fieldErrors
+	fe.name("name", body.Name)
+	if len(body.Scopes) == 0 {
+		fe.add("scopes", "required")
+	}
+	for i, sc := range body.Scopes {
+		if !auth.ValidScope(sc) {
+			fe.add("scopes."+strconv.Itoa(i), "unknown scope "+sc)
+		}
+	}
+	if fe.failed(w) {
+		return
+	}
+
+	key, hash, err := auth.GenerateKey()
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+	k, err := s.store.CreateAPIKey(r.Context(), body.Name, body.Scopes, hash)
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+
+	// The plain key is returned only once; the server keeps just its hash.
+	writeJSON(w, 201, map[string]interface{}{"api_key": k, "key": key})
+}
+
+func (s *Server) handleListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(405)
+		return
+	}
+	keys, err := s.store.ListAPIKeys(r.Context())
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+	writeJSON(w, 200, map[string]interface{}{"api_keys": keys})
+}
+
+func (s *Server) handleRevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(405)
+		return
+	}
+	var body struct {
+		ID string `json:"id"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	var fe fieldErrors
+	fe.id("id", body.ID)
+	if fe.failed(w) {
+		return
+	}
+	if err := s.store.RevokeAPIKey(r.Context(), body.ID); err != nil {
+		if err == storage.ErrAPIKeyNotFound {
+			writeError(w, 404, "NOT_FOUND", "api key not found")
+			return
+		}
+		internalError(w, r, err)
+		return
+	}
+	writeJSON(w, 200, map[string]string{"status": "ok"})
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"Backend-trainee-assignment-autumn-2025/internal/policy"
+	"Backend-trainee-assignment-autumn-2025/internal/storage"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(405)
+		return
+	}
+	q := r.URL.Query()
+
+	f := storage.AuditFilter{
+		Actor:      q.Get("actor"),
+		Action:     q.Get("action"),
+		EntityType: q.Get("entity_type"),
+		EntityID:   q.Get("entity_id"),
+		Limit:      defaultAuditLimit,
+	}
+	var fe fieldErrors
+	f.From, f.To = fe.timeRange(q)
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxAuditLimit {
+			fe.add("limit", "must be between 1 and 500")
+		} else {
+			f.Limit = n
+		}
+	}
+	if v := q.Get("cursor"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			fe.add("cursor", "must be next_cursor of a previous page")
+		} else {
+			f.BeforeID = id
+		}
+	}
+	if fe.failed(w) {
+		return
+	}
+
+	entries, err := s.guard.ListAudit(r.Context(), f)
+	if err == policy.ErrForbidden {
+		writeError(w, 403, "FORBIDDEN", "only admins can read the audit log")
+		return
+	}
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+
+	resp := map[string]interface{}{"entries": entries}
+	if len(entries) == f.Limit {
+		resp["next_cursor"] = strconv.FormatInt(entries[len(entries)-1].ID, 10)
+	}
+	writeJSON(w, 200, resp)
+}
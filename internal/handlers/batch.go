@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"time"
+
+	"Backend-trainee-assignment-autumn-2025/internal/logging"
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+	"Backend-trainee-assignment-autumn-2025/internal/policy"
+	"Backend-trainee-assignment-autumn-2025/internal/storage"
+)
+
+const (
+	// maxBatchSize bounds /pullRequest/batchCreate; larger sets go through
+	// the NDJSON import.
+	maxBatchSize = 1000
+	// importChunk is how many import lines are written per transaction.
+	importChunk = 500
+)
+
+type createPRInput struct {
+	ID     string `json:"pull_request_id"`
+	Name   string `json:"pull_request_name"`
+	Author string `json:"author_id"`
+}
+
+func (in createPRInput) validate(fe *fieldErrors, prefix string) {
+	fe.id(prefix+"pull_request_id", in.ID)
+	fe.name(prefix+"pull_request_name", in.Name)
+	fe.id(prefix+"author_id", in.Author)
+}
+
+func (in createPRInput) pr() models.PullRequest {
+	return models.PullRequest{PullRequestID: in.ID, PullRequestName: in.Name, AuthorID: in.Author}
+}
+
+// handleBatchCreatePRs creates a JSON array of pull requests. A malformed
+// item rejects the whole request; an existing ID, an unknown author or an
+// author the caller may not act for only fails that item.
+func (s *Server) handleBatchCreatePRs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(405)
+		return
+	}
+	var body []createPRInput
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	var fe fieldErrors
+	if len(body) == 0 || len(body) > maxBatchSize {
+		fe.add("", fmt.Sprintf("must contain 1 to %d pull requests", maxBatchSize))
+	}
+	for i, in := range body {
+		in.validate(&fe, fmt.Sprintf("%d.", i))
+	}
+	if fe.failed(w) {
+		return
+	}
+
+	prs := make([]models.PullRequest, len(body))
+	for i, in := range body {
+		prs[i] = in.pr()
+	}
+	results, err := s.guard.CreatePRs(r.Context(), prs)
+	if err == storage.ErrPRExists {
+		writeError(w, 409, "PR_EXISTS", "a PR of the batch was created concurrently; retry the batch")
+		return
+	}
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+
+	resp := models.BatchPRResponse{Created: []models.BatchPRResult{}, Failed: []models.BatchPRResult{}}
+	for i, res := range results {
+		item := s.batchResult(i, res)
+		if item.Error != nil {
+			resp.Failed = append(resp.Failed, item)
+		} else {
+			resp.Created = append(resp.Created, item)
+		}
+	}
+	writeJSON(w, 200, resp)
+}
+
+func (s *Server) batchResult(index int, res storage.PRCreateResult) models.BatchPRResult {
+	item := models.BatchPRResult{Index: index, PullRequestID: res.PR.PullRequestID}
+	switch res.Err {
+	case nil:
+		pr := res.PR
+		item.PR = &pr
+		s.notifier.ReviewersAssigned(pr, pr.AssignedReviewers)
+	case storage.ErrPRExists:
+		item.Error = &models.ItemError{Code: "PR_EXISTS", Message: "PR id already exists"}
+	case storage.ErrUserNotFound:
+		item.Error = &models.ItemError{Code: "NOT_FOUND", Message: "author/team not found"}
+	case policy.ErrForbidden:
+		item.Error = &models.ItemError{Code: "FORBIDDEN", Message: "only the author, their team lead or an admin can open this PR"}
+	}
+	return item
+}
+
+// handleImportPRs streams newline-delimited JSON pull requests, one object
+// per line in the /pullRequest/create format, and answers with one result
+// line per input line as each chunk is committed. Invalid lines fail on
+// their own. Once results are streaming, a failure that stops the import is
+// reported as a final ErrorResponse line; chunks already answered stay
+// committed.
+func (s *Server) handleImportPRs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(405)
+		return
+	}
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt != ndjson {
+		writeInvalid(w, []models.FieldError{{Field: "Content-Type", Reason: "must be " + ndjson}})
+		return
+	}
+
+	// An import may run well past the server's read and write timeouts;
+	// it ends when the body does or the client goes away.
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	sc := bufio.NewScanner(r.Body)
+	sc.Buffer(make([]byte, 0, 64*1024), maxBodyBytes)
+	out := &ndjsonWriter{w: w}
+
+	var (
+		pending []models.BatchPRResult
+		prs     []models.PullRequest
+		slots   []int
+		index   int
+		line    int
+	)
+	flush := func() error {
+		if len(prs) > 0 {
+			results, err := s.guard.CreatePRs(r.Context(), prs)
+			if err != nil {
+				return err
+			}
+			for i, res := range results {
+				item := s.batchResult(pending[slots[i]].Index, res)
+				item.Line = pending[slots[i]].Line
+				pending[slots[i]] = item
+			}
+		}
+		for _, item := range pending {
+			out.write(item)
+		}
+		out.flush()
+		pending, prs, slots = pending[:0], prs[:0], slots[:0]
+		return nil
+	}
+
+	for sc.Scan() {
+		line++
+		raw := bytes.TrimSpace(sc.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		item := models.BatchPRResult{Index: index, Line: line}
+		index++
+
+		var in createPRInput
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		err := dec.Decode(&in)
+		if err == nil && dec.More() {
+			err = errors.New("unexpected data after the JSON value")
+		}
+		var fe fieldErrors
+		if err != nil {
+			fe = append(fe, bodyError(err))
+		} else {
+			item.PullRequestID = in.ID
+			in.validate(&fe, "")
+		}
+		if len(fe) > 0 {
+			item.Error = &models.ItemError{Code: "INVALID", Message: fe.message(), Details: fe}
+			pending = append(pending, item)
+			continue
+		}
+
+		slots = append(slots, len(pending))
+		pending = append(pending, item)
+		prs = append(prs, in.pr())
+		if len(prs) == importChunk {
+			if err := flush(); err != nil {
+				s.importFailed(w, r, out, err)
+				return
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		s.importFailed(w, r, out, importReadError(err, line+1))
+		return
+	}
+	if err := flush(); err != nil {
+		s.importFailed(w, r, out, err)
+		return
+	}
+	if !out.started {
+		// Empty input: still answer with the stream content type.
+		out.start()
+	}
+}
+
+type importError struct {
+	status int
+	msg    string
+}
+
+func (e *importError) Error() string { return e.msg }
+
+func importReadError(err error, line int) error {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		return &importError{http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit)}
+	case errors.Is(err, bufio.ErrTooLong):
+		return &importError{http.StatusBadRequest, fmt.Sprintf("line %d exceeds %d bytes", line, maxBodyBytes)}
+	}
+	return &importError{http.StatusBadRequest, "read body: " + err.Error()}
+}
+
+// importFailed reports an error that stops the import: as a regular error
+// response if nothing was sent yet, otherwise as the last line.
+func (s *Server) importFailed(w http.ResponseWriter, r *http.Request, out *ndjsonWriter, err error) {
+	var e models.ErrorResponse
+	var ie *importError
+	switch {
+	case errors.As(err, &ie):
+		if !out.started {
+			writeError(w, ie.status, "INVALID", ie.msg)
+			return
+		}
+		e.Error.Code, e.Error.Message = "INVALID", ie.msg
+	case err == storage.ErrPRExists:
+		if !out.started {
+			writeError(w, 409, "PR_EXISTS", "a PR of the import was created concurrently; retry the import")
+			return
+		}
+		e.Error.Code, e.Error.Message = "PR_EXISTS", "a PR of the import was created concurrently; retry from the first line without a result"
+	default:
+		if !out.started {
+			internalError(w, r, err)
+			return
+		}
+		logging.FromContext(r.Context()).Error("import failed", "path", r.URL.Path, "error", err)
+		e.Error.Code, e.Error.Message = "ERROR", "internal error"
+		e.Error.RequestID = logging.RequestID(r.Context())
+	}
+	out.write(e)
+	out.flush()
+}
+
+const ndjson = "application/x-ndjson"
+
+// ndjsonWriter writes one JSON value per line, sending the 200 header with
+// the first one.
+type ndjsonWriter struct {
+	w       http.ResponseWriter
+	started bool
+}
+
+func (o *ndjsonWriter) start() {
+	o.w.Header().Set("Content-Type", ndjson)
+	o.w.WriteHeader(http.StatusOK)
+	o.started = true
+}
+
+func (o *ndjsonWriter) write(v interface{}) {
+	if !o.started {
+		o.start()
+	}
+	if err := json.NewEncoder(o.w).Encode(v); err != nil {
+		slog.Error("encode response", "error", err)
+	}
+}
+
+func (o *ndjsonWriter) flush() {
+	_ = http.NewResponseController(o.w).Flush()
+}
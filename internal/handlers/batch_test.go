@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+)
+
+func TestBatchCreateRejectsMalformedItems(t *testing.T) {
+	s := &Server{}
+	r := httptest.NewRequest(http.MethodPost, "/pullRequest/batchCreate", strings.NewReader(
+		`[{"pull_request_id":"pr-1","pull_request_name":"ok","author_id":"u1"},
+		  {"pull_request_id":"pr 2","pull_request_name":"","author_id":"u1"}]`))
+	w := httptest.NewRecorder()
+	s.handleBatchCreatePRs(w, r)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	var er models.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &er))
+	var fields []string
+	for _, d := range er.Error.Details {
+		fields = append(fields, d.Field)
+	}
+	require.Equal(t, []string{"1.pull_request_id", "1.pull_request_name"}, fields)
+}
+
+// Lines that fail validation are answered without touching the store,
+// which is nil here.
+func TestImportReportsInvalidLines(t *testing.T) {
+	s := &Server{}
+	body := strings.Join([]string{
+		`{"pull_request_id":"pr-1","pull_request_name":"n","author_id":"u 1"}`,
+		``,
+		`not json`,
+		`{"pull_request_id":"pr-3","pull_request_name":"n","author_id":"u1","x":1}`,
+	}, "\n")
+	r := httptest.NewRequest(http.MethodPost, "/pullRequest/import", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-ndjson")
+	w := httptest.NewRecorder()
+	s.handleImportPRs(w, r)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	var got []models.BatchPRResult
+	sc := bufio.NewScanner(w.Body)
+	for sc.Scan() {
+		var res models.BatchPRResult
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &res))
+		got = append(got, res)
+	}
+	require.Len(t, got, 3)
+	for i, line := range []int{1, 3, 4} {
+		require.Equal(t, i, got[i].Index)
+		require.Equal(t, line, got[i].Line)
+		require.Equal(t, "INVALID", got[i].Error.Code)
+	}
+	require.Equal(t, "author_id", got[0].Error.Details[0].Field)
+	require.Equal(t, "x", got[2].Error.Details[0].Field)
+}
+
+func TestImportRequiresNDJSON(t *testing.T) {
+	s := &Server{}
+	r := httptest.NewRequest(http.MethodPost, "/pullRequest/import", strings.NewReader(`[]`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	s.handleImportPRs(w, r)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"Backend-trainee-assignment-autumn-2025/internal/logging"
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+	"Backend-trainee-assignment-autumn-2025/internal/storage"
+)
+
+const (
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+
+	// exportFlushRows is how many rows are sent to the client at a time.
+	exportFlushRows = 500
+)
+
+// exportParams reads the format, team_name, from and to query parameters
+// shared by the export endpoints.
+func exportParams(w http.ResponseWriter, r *http.Request) (storage.ExportFilter, string, bool) {
+	q := r.URL.Query()
+	var fe fieldErrors
+	f := storage.ExportFilter{TeamName: q.Get("team_name")}
+	if f.TeamName != "" {
+		fe.name("team_name", f.TeamName)
+	}
+	f.From, f.To = fe.timeRange(q)
+	format := q.Get("format")
+	switch format {
+	case "":
+		format = formatNDJSON
+	case formatCSV, formatNDJSON:
+	default:
+		fe.add("format", "must be csv or ndjson")
+	}
+	return f, format, !fe.failed(w)
+}
+
+func (s *Server) handleExportUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(405)
+		return
+	}
+	f, format, ok := exportParams(w, r)
+	if !ok {
+		return
+	}
+	e := newExporter(w, format, "users",
+		[]string{"team_name", "user_id", "username", "is_active", "email", "daily_digest"})
+	err := s.guard.ExportUsers(r.Context(), f, func(u models.User) error {
+		return e.write(u, []string{u.TeamName, u.UserID, u.Username,
+			strconv.FormatBool(u.IsActive), u.Email, strconv.FormatBool(u.DailyDigest)})
+	})
+	e.finish(r, err)
+}
+
+func (s *Server) handleExportPRs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(405)
+		return
+	}
+	f, format, ok := exportParams(w, r)
+	if !ok {
+		return
+	}
+	e := newExporter(w, format, "pull_requests",
+		[]string{"pull_request_id", "pull_request_name", "author_id", "team_name", "status",
+			"created_at", "merged_at", "stale_at", "closed_at"})
+	err := s.store.ExportPRs(r.Context(), f, func(p models.PRRecord) error {
+		return e.write(p, []string{p.PullRequestID, p.PullRequestName, p.AuthorID, p.TeamName, p.Status,
+			csvTime(&p.CreatedAt), csvTime(p.MergedAt), csvTime(p.StaleAt), csvTime(p.ClosedAt)})
+	})
+	e.finish(r, err)
+}
+
+func (s *Server) handleExportReviewers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(405)
+		return
+	}
+	f, format, ok := exportParams(w, r)
+	if !ok {
+		return
+	}
+	e := newExporter(w, format, "pr_reviewers",
+		[]string{"pull_request_id", "user_id", "team_name", "assigned_at", "sla_escalated_at"})
+	err := s.store.ExportReviewers(r.Context(), f, func(rr models.ReviewerRecord) error {
+		return e.write(rr, []string{rr.PullRequestID, rr.UserID, rr.TeamName,
+			csvTime(&rr.AssignedAt), csvTime(rr.SLAEscalatedAt)})
+	})
+	e.finish(r, err)
+}
+
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// csvSafe keeps spreadsheets from reading a cell as a formula: values
+// starting with =, +, -, @, tab or CR get a leading quote.
+func csvSafe(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+// exporter writes rows as CSV with a header line or as NDJSON, flushing
+// every exportFlushRows rows. The 200 is sent with the first row, so an
+// error before it is still a regular error response.
+type exporter struct {
+	w       http.ResponseWriter
+	format  string
+	name    string
+	header  []string
+	csv     *csv.Writer
+	json    *json.Encoder
+	rows    int
+	started bool
+}
+
+func newExporter(w http.ResponseWriter, format, name string, header []string) *exporter {
+	// Large exports outlast the server's write timeout.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	return &exporter{w: w, format: format, name: name, header: header}
+}
+
+func (e *exporter) start() error {
+	e.started = true
+	ext, ctype := ".ndjson", ndjson
+	if e.format == formatCSV {
+		ext, ctype = ".csv", "text/csv; charset=utf-8"
+	}
+	e.w.Header().Set("Content-Type", ctype)
+	e.w.Header().Set("Content-Disposition", `attachment; filename="`+e.name+ext+`"`)
+	e.w.WriteHeader(http.StatusOK)
+	if e.format == formatCSV {
+		e.csv = csv.NewWriter(e.w)
+		return e.csv.Write(e.header)
+	}
+	e.json = json.NewEncoder(e.w)
+	return nil
+}
+
+func (e *exporter) write(v interface{}, row []string) error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+	var err error
+	if e.csv != nil {
+		for i, cell := range row {
+			row[i] = csvSafe(cell)
+		}
+		err = e.csv.Write(row)
+	} else {
+		err = e.json.Encode(v)
+	}
+	if err != nil {
+		return err
+	}
+	e.rows++
+	if e.rows%exportFlushRows == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+func (e *exporter) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if err := http.NewResponseController(e.w).Flush(); err != nil && err != http.ErrNotSupported {
+		return err
+	}
+	return nil
+}
+
+// finish completes the export. An error after rows were sent ends an NDJSON
+// export with an ErrorResponse line; CSV has no place for one, so the
+// response is aborted and the client sees a truncated transfer instead of a
+// complete-looking file.
+func (e *exporter) finish(r *http.Request, err error) {
+	if err == nil && !e.started {
+		err = e.start()
+	}
+	if err == nil {
+		err = e.flush()
+	}
+	if err == nil {
+		return
+	}
+	if !e.started {
+		internalError(e.w, r, err)
+		return
+	}
+
+	logging.FromContext(r.Context()).Error("export failed", "path", r.URL.Path, "rows", e.rows, "error", err)
+	if e.json != nil {
+		var er models.ErrorResponse
+		er.Error.Code = "ERROR"
+		er.Error.Message = "internal error"
+		er.Error.RequestID = logging.RequestID(r.Context())
+		if e.json.Encode(er) == nil {
+			return
+		}
+	}
+	panic(http.ErrAbortHandler)
+}
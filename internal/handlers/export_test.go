@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportParams(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/export/pullRequests?team_name=backend&from=2025-10-01T00:00:00Z", nil)
+	w := httptest.NewRecorder()
+	f, format, ok := exportParams(w, r)
+	require.True(t, ok)
+	require.Equal(t, formatNDJSON, format)
+	require.Equal(t, "backend", f.TeamName)
+	require.NotNil(t, f.From)
+	require.Nil(t, f.To)
+
+	r = httptest.NewRequest(http.MethodGet, "/export/pullRequests?format=xml&from=2025-10-02T00:00:00Z&to=2025-10-01T00:00:00Z", nil)
+	w = httptest.NewRecorder()
+	_, _, ok = exportParams(w, r)
+	require.False(t, ok)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), `"field":"to"`)
+	require.Contains(t, w.Body.String(), `"field":"format"`)
+}
+
+func TestExporterCSV(t *testing.T) {
+	w := httptest.NewRecorder()
+	e := newExporter(w, formatCSV, "users", []string{"user_id", "username"})
+	require.NoError(t, e.write(nil, []string{"u1", "Alice, Jr."}))
+	e.finish(httptest.NewRequest(http.MethodGet, "/export/users", nil), nil)
+
+	require.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	require.Equal(t, `attachment; filename="users.csv"`, w.Header().Get("Content-Disposition"))
+	require.Equal(t, "user_id,username\nu1,\"Alice, Jr.\"\n", w.Body.String())
+}
+
+func TestExporterCSVFormulas(t *testing.T) {
+	w := httptest.NewRecorder()
+	e := newExporter(w, formatCSV, "users", []string{"user_id", "username"})
+	for _, name := range []string{"=HYPERLINK(\"x\")", "+1", "-2+3", "@SUM(A1)", "\tx", "\rx", "Bob=1"} {
+		require.NoError(t, e.write(nil, []string{"u1", name}))
+	}
+	e.finish(httptest.NewRequest(http.MethodGet, "/export/users", nil), nil)
+
+	require.Equal(t, "user_id,username\n"+
+		"u1,\"'=HYPERLINK(\"\"x\"\")\"\n"+
+		"u1,'+1\n"+
+		"u1,'-2+3\n"+
+		"u1,'@SUM(A1)\n"+
+		"u1,'\tx\n"+
+		"u1,\"'\rx\"\n"+
+		"u1,Bob=1\n", w.Body.String())
+}
+
+// An empty export is still a valid file: the header line, or nothing.
+func TestExporterEmpty(t *testing.T) {
+	w := httptest.NewRecorder()
+	newExporter(w, formatCSV, "users", []string{"user_id"}).finish(httptest.NewRequest(http.MethodGet, "/", nil), nil)
+	require.Equal(t, "user_id\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	newExporter(w, formatNDJSON, "users", nil).finish(httptest.NewRequest(http.MethodGet, "/", nil), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	require.Empty(t, w.Body.String())
+}
+
+func TestExporterErrors(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/export/users", nil)
+
+	w := httptest.NewRecorder()
+	newExporter(w, formatNDJSON, "users", nil).finish(r, errors.New("connection refused"))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+
+	w = httptest.NewRecorder()
+	e := newExporter(w, formatNDJSON, "users", nil)
+	require.NoError(t, e.write(map[string]string{"user_id": "u1"}, nil))
+	e.finish(r, errors.New("connection reset"))
+	require.Equal(t, "{\"user_id\":\"u1\"}\n{\"error\":{\"code\":\"ERROR\",\"message\":\"internal error\"}}\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	e = newExporter(w, formatCSV, "users", []string{"user_id"})
+	require.NoError(t, e.write(nil, []string{"u1"}))
+	require.PanicsWithValue(t, http.ErrAbortHandler, func() { e.finish(r, errors.New("connection reset")) })
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"Backend-trainee-assignment-autumn-2025/internal/fairness"
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+	"Backend-trainee-assignment-autumn-2025/internal/policy"
+	"Backend-trainee-assignment-autumn-2025/internal/storage"
+)
+
+// maxRebalanceMoves bounds one /team/rebalance call and is its default.
+const maxRebalanceMoves = 100
+
+func (s *Server) handleFairness(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(405)
+		return
+	}
+	q := r.URL.Query()
+	var fe fieldErrors
+	teamName := q.Get("team_name")
+	fe.name("team_name", teamName)
+	from, to := fe.timeRange(q)
+	if fe.failed(w) {
+		return
+	}
+
+	members, err := s.store.TeamLoad(r.Context(), teamName, from, to)
+	if err == storage.ErrTeamNotFound {
+		writeError(w, 404, "NOT_FOUND", "team not found")
+		return
+	}
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+	report := fairness.Report(members)
+	report.TeamName, report.From, report.To = teamName, from, to
+	writeJSON(w, 200, report)
+}
+
+// handleRebalance moves open reviews from the most to the least loaded
+// active members of a team.
+func (s *Server) handleRebalance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(405)
+		return
+	}
+	var body struct {
+		TeamName string `json:"team_name"`
+		MaxMoves int    `json:"max_moves"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	var fe fieldErrors
+	fe.name("team_name", body.TeamName)
+	if body.MaxMoves == 0 {
+		body.MaxMoves = maxRebalanceMoves
+	}
+	if body.MaxMoves < 0 || body.MaxMoves > maxRebalanceMoves {
+		fe.add("max_moves", fmt.Sprintf("must be between 1 and %d", maxRebalanceMoves))
+	}
+	if fe.failed(w) {
+		return
+	}
+
+	res, err := s.guard.RebalanceTeam(r.Context(), body.TeamName, body.MaxMoves)
+	switch err {
+	case nil:
+	case policy.ErrForbidden:
+		writeError(w, 403, "FORBIDDEN", "not allowed to manage this team")
+		return
+	case storage.ErrTeamNotFound:
+		writeError(w, 404, "NOT_FOUND", "team not found")
+		return
+	default:
+		internalError(w, r, err)
+		return
+	}
+
+	resp := models.RebalanceResult{
+		TeamName: body.TeamName,
+		Moves:    make([]models.RebalanceMove, 0, len(res.Moves)),
+		Before:   res.Before,
+		After:    res.After,
+	}
+	for _, mv := range res.Moves {
+		s.notifier.ReviewersAssigned(mv.PR, []string{mv.NewUserID})
+		resp.Moves = append(resp.Moves, models.RebalanceMove{
+			PullRequestID: mv.PR.PullRequestID, FromUserID: mv.OldUserID, ToUserID: mv.NewUserID,
+		})
+	}
+	writeJSON(w, 200, resp)
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFairnessParams(t *testing.T) {
+	s := &Server{}
+	w := httptest.NewRecorder()
+	s.handleFairness(w, httptest.NewRequest(http.MethodGet, "/team/fairness?from=2025-10-02T00:00:00Z&to=2025-10-01T00:00:00Z", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), `"field":"team_name"`)
+	require.Contains(t, w.Body.String(), `"field":"to"`)
+}
+
+func TestRebalanceParams(t *testing.T) {
+	s := &Server{}
+	w := httptest.NewRecorder()
+	s.handleRebalance(w, httptest.NewRequest(http.MethodPost, "/team/rebalance",
+		strings.NewReader(`{"team_name":"backend","max_moves":1000}`)))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), `"field":"max_moves"`)
+}
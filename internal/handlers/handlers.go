@@ -3,43 +3,191 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
+	"net/mail"
+	"strconv"
 	"strings"
+	"time"
 
+	"Backend-trainee-assignment-autumn-2025/internal/auth"
+	"Backend-trainee-assignment-autumn-2025/internal/logging"
+	"Backend-trainee-assignment-autumn-2025/internal/metrics"
 	"Backend-trainee-assignment-autumn-2025/internal/models"
+	"Backend-trainee-assignment-autumn-2025/internal/notify"
+	"Backend-trainee-assignment-autumn-2025/internal/openapi"
+	"Backend-trainee-assignment-autumn-2025/internal/policy"
+	"Backend-trainee-assignment-autumn-2025/internal/sla"
 	"Backend-trainee-assignment-autumn-2025/internal/storage"
+	"Backend-trainee-assignment-autumn-2025/internal/tracing"
 )
 
 type Server struct {
-	store *storage.Store
+	store    *storage.Store
+	notifier *notify.Notifier
+	sla      *sla.Monitor
+	auth     *auth.Authenticator
+	guard    *policy.Guard
+	shutdown context.Context
+	contract *openapi.Validator
+
+	idempotencyTTL time.Duration
 }
 
-func RegisterHandlers(mux *http.ServeMux, st *storage.Store) {
-	s := &Server{store: st}
-
-	mux.HandleFunc("/team/add", s.handleTeamAdd)
-	mux.HandleFunc("/team/get", s.handleTeamGet)
-	mux.HandleFunc("/users/setIsActive", s.handleSetIsActive)
-	mux.HandleFunc("/pullRequest/create", s.handleCreatePR)
-	mux.HandleFunc("/pullRequest/merge", s.handleMergePR)
-	mux.HandleFunc("/pullRequest/reassign", s.handleReassign)
-	mux.HandleFunc("/users/getReview", s.handleGetReview)
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(200)
-		_, _ = w.Write([]byte(`{"status":"ok"}`))
-	})
-	mux.HandleFunc("/stats", s.handleStats)
-	mux.HandleFunc("/team/deactivateUsers", s.handleDeactivateUsers)
+type Option func(*Server)
+
+func WithNotifier(n *notify.Notifier) Option {
+	return func(s *Server) {
+		s.notifier = n
+	}
+}
+
+func WithSLAMonitor(m *sla.Monitor) Option {
+	return func(s *Server) {
+		s.sla = m
+	}
+}
+
+// WithAuthenticator turns on authentication: every route except the health
+// probes and /metrics then requires credentials carrying the route's scope.
+func WithAuthenticator(a *auth.Authenticator) Option {
+	return func(s *Server) {
+		s.auth = a
+	}
+}
+
+// WithValidator checks every route's traffic against openapi.yml.
+func WithValidator(v *openapi.Validator) Option {
+	return func(s *Server) {
+		s.contract = v
+	}
+}
+
+func RegisterHandlers(mux *http.ServeMux, st *storage.Store, opts ...Option) {
+	s := &Server{store: st, guard: policy.NewGuard(st), idempotencyTTL: defaultIdempotencyTTL}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.sla == nil {
+		s.sla = sla.NewMonitor(st, s.notifier, sla.DefaultCalendar)
+	}
+	s.routes(mux)
+}
+
+// routes registers every route of s on mux with the scope it requires.
+func (s *Server) routes(mux *http.ServeMux) {
+	// Callers are authenticated before the request is checked against the
+	// contract, so an anonymous caller learns nothing about the schema.
+	handle := func(pattern string, maxBody int64, scope string, h http.HandlerFunc) {
+		var handler http.Handler = h
+		if s.contract != nil {
+			handler = s.contract.Middleware(pattern, handler)
+		}
+		if scope != public {
+			handler = s.require(scope, handler.ServeHTTP)
+		}
+		handler = http.MaxBytesHandler(handler, maxBody)
+		mux.Handle(pattern, tracing.Middleware(pattern, metrics.Instrument(pattern, handler)))
+	}
+	route := func(pattern, scope string, h http.HandlerFunc) {
+		handle(pattern, maxBodyBytes, scope, h)
+	}
+
+	route("/team/add", auth.ScopeAdminTeams, s.idempotent(s.handleTeamAdd))
+	route("/team/get", auth.ScopeRead, s.handleTeamGet)
+	// Users may change their own activity and notifications; the guard
+	// decides whose settings a caller may touch.
+	route("/users/setIsActive", auth.ScopeWritePRs, s.idempotent(s.handleSetIsActive))
+	route("/users/setNotifications", auth.ScopeWritePRs, s.idempotent(s.handleSetNotifications))
+	route("/users/setRoles", auth.ScopeAdminTeams, s.idempotent(s.handleSetRoles))
+	route("/pullRequest/create", auth.ScopeWritePRs, s.idempotent(s.handleCreatePR))
+	route("/pullRequest/merge", auth.ScopeWritePRs, s.idempotent(s.handleMergePR))
+	route("/pullRequest/reassign", auth.ScopeWritePRs, s.idempotent(s.handleReassign))
+	route("/pullRequest/batchCreate", auth.ScopeWritePRs, s.idempotent(s.handleBatchCreatePRs))
+	handle("/pullRequest/import", maxImportBytes, auth.ScopeWritePRs, s.handleImportPRs)
+	route("/pullRequest/list", auth.ScopeRead, s.handleListPRs)
+	route("/pullRequest/timeline", auth.ScopeRead, s.handlePRTimeline)
+	route("/users/getReview", auth.ScopeRead, s.handleGetReview)
+	route("/health", public, s.handleLivez)
+	route("/livez", public, s.handleLivez)
+	route("/readyz", public, s.handleReadyz)
+	route("/stats", auth.ScopeRead, s.handleStats)
+	route("/team/deactivateUsers", auth.ScopeAdminTeams, s.idempotent(s.handleDeactivateUsers))
+	route("/team/setSLA", auth.ScopeAdminTeams, s.idempotent(s.handleSetSLA))
+	route("/team/fairness", auth.ScopeRead, s.handleFairness)
+	route("/team/rebalance", auth.ScopeAdminTeams, s.idempotent(s.handleRebalance))
+	route("/sla/breaches", auth.ScopeRead, s.handleSLABreaches)
+	route("/admin/apiKeys/create", auth.ScopeAdminKeys, s.handleCreateAPIKey)
+	route("/admin/apiKeys/list", auth.ScopeAdminKeys, s.handleListAPIKeys)
+	route("/admin/apiKeys/revoke", auth.ScopeAdminKeys, s.idempotent(s.handleRevokeAPIKey))
+	route("/audit", auth.ScopeAdminTeams, s.handleAudit)
+	route("/export/users", auth.ScopeRead, s.handleExportUsers)
+	route("/export/pullRequests", auth.ScopeRead, s.handleExportPRs)
+	route("/export/reviewers", auth.ScopeRead, s.handleExportReviewers)
+	route("/admin/snapshot", auth.ScopeAdminTeams, s.handleSnapshot)
+	handle("/admin/snapshot/restore", maxImportBytes, auth.ScopeAdminTeams, s.handleRestoreSnapshot)
+	mux.Handle("/metrics", metrics.Handler())
+}
+
+// public marks a route that needs no credentials.
+const public = ""
+
+// require authenticates the request and checks that the caller holds scope.
+// Without an authenticator every request is let through.
+func (s *Server) require(scope string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.auth == nil {
+			h(w, r)
+			return
+		}
+
+		p, err := s.auth.Authenticate(r)
+		switch {
+		case errors.Is(err, auth.ErrNoCredentials):
+			w.Header().Set("WWW-Authenticate", `Bearer, ApiKey header="`+auth.APIKeyHeader+`"`)
+			writeError(w, 401, "UNAUTHORIZED", "credentials required")
+			return
+		case errors.Is(err, auth.ErrInvalidKey), errors.Is(err, auth.ErrInvalidToken):
+			w.Header().Set("WWW-Authenticate", `Bearer, ApiKey header="`+auth.APIKeyHeader+`"`)
+			writeError(w, 401, "UNAUTHORIZED", "invalid credentials")
+			return
+		case err != nil:
+			internalError(w, r, err)
+			return
+		}
+
+		if !p.HasScope(scope) {
+			writeError(w, 403, "FORBIDDEN", "missing scope "+scope)
+			return
+		}
+
+		h(w, r.WithContext(auth.WithPrincipal(r.Context(), p)))
+	}
 }
 
 func writeJSON(w http.ResponseWriter, code int, v interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
 	if err := json.NewEncoder(w).Encode(v); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		slog.Error("encode response", "error", err)
 	}
 }
 
+// internalError logs err with the request ID and sends the client a generic
+// 500 that carries only that ID.
+func internalError(w http.ResponseWriter, r *http.Request, err error) {
+	id := logging.RequestID(r.Context())
+	logging.FromContext(r.Context()).Error("internal error",
+		"method", r.Method, "path", r.URL.Path, "error", err)
+
+	var e models.ErrorResponse
+	e.Error.Code = "ERROR"
+	e.Error.Message = "internal error"
+	e.Error.RequestID = id
+	writeJSON(w, 500, e)
+}
+
 func writeError(w http.ResponseWriter, httpCode int, code, msg string) {
 	var e models.ErrorResponse
 	e.Error.Code = code
@@ -53,16 +201,28 @@ func (s *Server) handleTeamAdd(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	var t models.Team
-	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
-		writeError(w, 400, "INVALID", "bad request")
+	if !decodeBody(w, r, &t) {
 		return
 	}
-	if t.TeamName == "" {
-		writeError(w, 400, "INVALID", "team_name required")
+	var fe fieldErrors
+	fe.name("team_name", t.TeamName)
+	for i, m := range t.Members {
+		field := "members." + strconv.Itoa(i) + "."
+		fe.id(field+"user_id", m.UserID)
+		fe.name(field+"username", m.Username)
+		if msg := validateNotifications(m.Email, m.DailyDigest); msg != "" {
+			fe.add(field+"email", msg)
+		}
+	}
+	if fe.failed(w) {
 		return
 	}
-	if err := s.store.UpsertTeam(context.Background(), t); err != nil {
-		writeError(w, 500, "ERROR", err.Error())
+	if err := s.guard.UpsertTeam(r.Context(), t); err != nil {
+		if err == policy.ErrForbidden {
+			writeError(w, 403, "FORBIDDEN", "not allowed to manage this team")
+			return
+		}
+		internalError(w, r, err)
 		return
 	}
 	writeJSON(w, 201, map[string]models.Team{"team": t})
@@ -74,17 +234,18 @@ func (s *Server) handleTeamGet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	tn := r.URL.Query().Get("team_name")
-	if tn == "" {
-		writeError(w, 400, "INVALID", "team_name required")
+	var fe fieldErrors
+	fe.name("team_name", tn)
+	if fe.failed(w) {
 		return
 	}
-	t, err := s.store.GetTeam(context.Background(), tn)
+	t, err := s.store.GetTeam(r.Context(), tn)
 	if err != nil {
 		if err == storage.ErrTeamNotFound {
 			writeError(w, 404, "NOT_FOUND", "team not found")
 			return
 		}
-		writeError(w, 500, "ERROR", err.Error())
+		internalError(w, r, err)
 		return
 	}
 	writeJSON(w, 200, t)
@@ -99,22 +260,120 @@ func (s *Server) handleSetIsActive(w http.ResponseWriter, r *http.Request) {
 		UserID   string `json:"user_id"`
 		IsActive bool   `json:"is_active"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		writeError(w, 400, "INVALID", "bad request")
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	var fe fieldErrors
+	fe.id("user_id", body.UserID)
+	if fe.failed(w) {
 		return
 	}
-	u, err := s.store.SetUserActive(context.Background(), body.UserID, body.IsActive)
+	u, err := s.guard.SetUserActive(r.Context(), body.UserID, body.IsActive)
 	if err != nil {
+		if err == policy.ErrForbidden {
+			writeError(w, 403, "FORBIDDEN", "not allowed to change this user")
+			return
+		}
+		if err == storage.ErrUserNotFound {
+			writeError(w, 404, "NOT_FOUND", "user not found")
+			return
+		}
+		internalError(w, r, err)
+		return
+	}
+	writeJSON(w, 200, map[string]models.User{"user": u})
+}
+
+func (s *Server) handleSetNotifications(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(405)
+		return
+	}
+	var body struct {
+		UserID      string `json:"user_id"`
+		Email       string `json:"email"`
+		DailyDigest bool   `json:"daily_digest"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	var fe fieldErrors
+	fe.id("user_id", body.UserID)
+	if msg := validateNotifications(body.Email, body.DailyDigest); msg != "" {
+		fe.add("email", msg)
+	}
+	if fe.failed(w) {
+		return
+	}
+	u, err := s.guard.SetUserNotifications(r.Context(), body.UserID, body.Email, body.DailyDigest)
+	if err != nil {
+		if err == policy.ErrForbidden {
+			writeError(w, 403, "FORBIDDEN", "not allowed to change this user")
+			return
+		}
 		if err == storage.ErrUserNotFound {
 			writeError(w, 404, "NOT_FOUND", "user not found")
 			return
 		}
-		writeError(w, 500, "ERROR", err.Error())
+		internalError(w, r, err)
 		return
 	}
 	writeJSON(w, 200, map[string]models.User{"user": u})
 }
 
+func (s *Server) handleSetRoles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(405)
+		return
+	}
+	var body struct {
+		UserID string   `json:"user_id"`
+		Roles  []string `json:"roles"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	var fe fieldErrors
+	fe.id("user_id", body.UserID)
+	if body.Roles == nil {
+		body.Roles = []string{}
+	}
+	for i, role := range body.Roles {
+		if !policy.ValidRole(role) {
+			fe.add("roles."+strconv.Itoa(i), "unknown role "+role)
+		}
+	}
+	if fe.failed(w) {
+		return
+	}
+
+	if err := s.guard.SetUserRoles(r.Context(), body.UserID, body.Roles); err != nil {
+		switch err {
+		case policy.ErrForbidden:
+			writeError(w, 403, "FORBIDDEN", "only admins can change roles")
+		case storage.ErrUserNotFound:
+			writeError(w, 404, "NOT_FOUND", "user not found")
+		default:
+			internalError(w, r, err)
+		}
+		return
+	}
+	writeJSON(w, 200, map[string]interface{}{"user_id": body.UserID, "roles": body.Roles})
+}
+
+func validateNotifications(email string, dailyDigest bool) string {
+	if email == "" {
+		if dailyDigest {
+			return "email required for daily_digest"
+		}
+		return ""
+	}
+	if _, err := mail.ParseAddress(email); err != nil {
+		return "invalid email"
+	}
+	return ""
+}
+
 func (s *Server) handleCreatePR(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		w.WriteHeader(405)
@@ -125,8 +384,14 @@ func (s *Server) handleCreatePR(w http.ResponseWriter, r *http.Request) {
 		Name   string `json:"pull_request_name"`
 		Author string `json:"author_id"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		writeError(w, 400, "INVALID", "bad request")
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	var fe fieldErrors
+	fe.id("pull_request_id", body.ID)
+	fe.name("pull_request_name", body.Name)
+	fe.id("author_id", body.Author)
+	if fe.failed(w) {
 		return
 	}
 	pr := models.PullRequest{
@@ -134,8 +399,12 @@ func (s *Server) handleCreatePR(w http.ResponseWriter, r *http.Request) {
 		PullRequestName: body.Name,
 		AuthorID:        body.Author,
 	}
-	created, err := s.store.CreatePR(context.Background(), pr)
+	created, err := s.guard.CreatePR(r.Context(), pr)
 	if err != nil {
+		if err == policy.ErrForbidden {
+			writeError(w, 403, "FORBIDDEN", "only the author, their team lead or an admin can open this PR")
+			return
+		}
 		if err == storage.ErrPRExists {
 			writeError(w, 409, "PR_EXISTS", "PR id already exists")
 			return
@@ -144,9 +413,10 @@ func (s *Server) handleCreatePR(w http.ResponseWriter, r *http.Request) {
 			writeError(w, 404, "NOT_FOUND", "author/team not found")
 			return
 		}
-		writeError(w, 500, "ERROR", err.Error())
+		internalError(w, r, err)
 		return
 	}
+	s.notifier.ReviewersAssigned(created, created.AssignedReviewers)
 	writeJSON(w, 201, map[string]models.PullRequest{"pr": created})
 }
 
@@ -158,17 +428,30 @@ func (s *Server) handleMergePR(w http.ResponseWriter, r *http.Request) {
 	var body struct {
 		ID string `json:"pull_request_id"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		writeError(w, 400, "INVALID", "bad request")
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	var fe fieldErrors
+	fe.id("pull_request_id", body.ID)
+	if fe.failed(w) {
 		return
 	}
-	pr, err := s.store.MergePR(context.Background(), body.ID)
+
+	pr, err := s.guard.MergePR(r.Context(), body.ID)
 	if err != nil {
+		if err == policy.ErrForbidden {
+			writeError(w, 403, "FORBIDDEN", "only the PR author or an admin can merge")
+			return
+		}
 		if err == storage.ErrPRNotFound {
 			writeError(w, 404, "NOT_FOUND", "PR not found")
 			return
 		}
-		writeError(w, 500, "ERROR", err.Error())
+		if err == storage.ErrPRClosed {
+			writeError(w, 409, "PR_CLOSED", "cannot merge closed PR")
+			return
+		}
+		internalError(w, r, err)
 		return
 	}
 	writeJSON(w, 200, map[string]models.PullRequest{"pr": pr})
@@ -180,20 +463,30 @@ func (s *Server) handleReassign(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	var body struct {
-		ID    string `json:"pull_request_id"`
-		OldID string `json:"old_user_id"`
+		ID     string `json:"pull_request_id"`
+		OldID  string `json:"old_user_id"`
+		DryRun bool   `json:"dry_run"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		writeError(w, 400, "INVALID", "bad request")
+	if !decodeBody(w, r, &body) {
 		return
 	}
-	pr, newID, err := s.store.ReassignReviewer(context.Background(), body.ID, body.OldID)
+	var fe fieldErrors
+	fe.id("pull_request_id", body.ID)
+	fe.id("old_user_id", body.OldID)
+	if fe.failed(w) {
+		return
+	}
+	pr, newID, err := s.guard.ReassignReviewer(r.Context(), body.ID, body.OldID, storage.ReasonManualReassign, body.DryRun)
 	if err != nil {
 		switch err {
+		case policy.ErrForbidden:
+			writeError(w, 403, "FORBIDDEN", "only the PR author, their team lead or an admin can reassign")
 		case storage.ErrPRNotFound:
 			writeError(w, 404, "NOT_FOUND", "PR not found")
 		case storage.ErrPRMerged:
 			writeError(w, 409, "PR_MERGED", "cannot reassign on merged PR")
+		case storage.ErrPRClosed:
+			writeError(w, 409, "PR_CLOSED", "cannot reassign on closed PR")
 		case storage.ErrNotAssigned:
 			writeError(w, 409, "NOT_ASSIGNED", "reviewer is not assigned to this PR")
 		case storage.ErrNoCandidate:
@@ -203,11 +496,16 @@ func (s *Server) handleReassign(w http.ResponseWriter, r *http.Request) {
 			if strings.Contains(msg, "user not found") {
 				writeError(w, 404, "NOT_FOUND", "user not found")
 			} else {
-				writeError(w, 500, "ERROR", msg)
+				internalError(w, r, err)
 			}
 		}
 		return
 	}
+	if body.DryRun {
+		writeJSON(w, 200, map[string]interface{}{"pr": pr, "replaced_by": newID, "dry_run": true})
+		return
+	}
+	s.notifier.ReviewersAssigned(pr, []string{newID})
 	writeJSON(w, 200, map[string]interface{}{"pr": pr, "replaced_by": newID})
 }
 
@@ -217,13 +515,15 @@ func (s *Server) handleGetReview(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	uid := r.URL.Query().Get("user_id")
-	if uid == "" {
-		writeError(w, 400, "INVALID", "user_id required")
+	var fe fieldErrors
+	fe.id("user_id", uid)
+	f := prFilterFromQuery(r, &fe)
+	if fe.failed(w) {
 		return
 	}
-	prs, err := s.store.GetPRsForReviewer(context.Background(), uid)
+	prs, err := s.store.GetPRsForReviewer(r.Context(), uid, f)
 	if err != nil {
-		writeError(w, 500, "ERROR", err.Error())
+		internalError(w, r, err)
 		return
 	}
 	resp := map[string]interface{}{
@@ -233,30 +533,101 @@ func (s *Server) handleGetReview(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, 200, resp)
 }
 
-func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleListPRs(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		w.WriteHeader(405)
 		return
 	}
+	var fe fieldErrors
+	f := prFilterFromQuery(r, &fe)
+	f.AuthorID = r.URL.Query().Get("author_id")
+	f.TeamName = r.URL.Query().Get("team_name")
+	if f.AuthorID != "" {
+		fe.id("author_id", f.AuthorID)
+	}
+	if f.TeamName != "" {
+		fe.name("team_name", f.TeamName)
+	}
+	if fe.failed(w) {
+		return
+	}
 
-	userStats, err := s.store.GetReviewerStats(context.Background())
+	prs, err := s.store.ListPRs(r.Context(), f)
 	if err != nil {
-		writeError(w, 500, "ERROR", err.Error())
+		internalError(w, r, err)
+		return
+	}
+	writeJSON(w, 200, map[string]interface{}{"pull_requests": prs})
+}
+
+func (s *Server) handlePRTimeline(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(405)
+		return
+	}
+	prID := r.URL.Query().Get("pull_request_id")
+	var fe fieldErrors
+	fe.id("pull_request_id", prID)
+	if fe.failed(w) {
 		return
 	}
 
-	prStats, err := s.store.GetPRStats(context.Background())
+	events, err := s.store.GetPRTimeline(r.Context(), prID)
+	if err == storage.ErrPRNotFound {
+		writeError(w, 404, "NOT_FOUND", "PR not found")
+		return
+	}
 	if err != nil {
-		writeError(w, 500, "ERROR", err.Error())
+		internalError(w, r, err)
 		return
 	}
+	writeJSON(w, 200, map[string]interface{}{"pull_request_id": prID, "events": events})
+}
 
-	resp := map[string]interface{}{
-		"reviewer_assignments": userStats,
-		"pr_assignments":       prStats,
+func prFilterFromQuery(r *http.Request, fe *fieldErrors) storage.PRFilter {
+	var f storage.PRFilter
+	q := r.URL.Query()
+
+	switch st := q.Get("status"); st {
+	case "", "OPEN", "MERGED", "CLOSED":
+		f.Status = st
+	default:
+		fe.add("status", "must be one of OPEN, MERGED, CLOSED")
 	}
 
-	writeJSON(w, 200, resp)
+	if v := q.Get("stale"); v != "" {
+		stale, err := strconv.ParseBool(v)
+		if err != nil {
+			fe.add("stale", "must be true or false")
+		} else {
+			f.Stale = &stale
+		}
+	}
+	return f
+}
+
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(405)
+		return
+	}
+	q := r.URL.Query()
+	var fe fieldErrors
+	f := storage.StatsFilter{TeamName: q.Get("team_name")}
+	if f.TeamName != "" {
+		fe.name("team_name", f.TeamName)
+	}
+	f.From, f.To = fe.timeRange(q)
+	if fe.failed(w) {
+		return
+	}
+
+	stats, err := s.store.GetStats(r.Context(), f)
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+	writeJSON(w, 200, stats)
 }
 
 func (s *Server) handleDeactivateUsers(w http.ResponseWriter, r *http.Request) {
@@ -268,25 +639,102 @@ func (s *Server) handleDeactivateUsers(w http.ResponseWriter, r *http.Request) {
 	var body struct {
 		TeamName string   `json:"team_name"`
 		UserIDs  []string `json:"user_ids"`
+		DryRun   bool     `json:"dry_run"`
+	}
+
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	var fe fieldErrors
+	fe.name("team_name", body.TeamName)
+	if len(body.UserIDs) == 0 {
+		fe.add("user_ids", "required")
+	}
+	for i, id := range body.UserIDs {
+		fe.id("user_ids."+strconv.Itoa(i), id)
+	}
+	if fe.failed(w) {
+		return
+	}
+
+	res, err := s.guard.BulkDeactivateUsers(r.Context(), body.TeamName, body.UserIDs, body.DryRun)
+	if err != nil {
+		if err == policy.ErrForbidden {
+			writeError(w, 403, "FORBIDDEN", "not allowed to manage this team")
+			return
+		}
+		internalError(w, r, err)
+		return
+	}
+	for _, a := range res.Added {
+		s.notifier.ReviewersAssigned(a.PR, []string{a.UserID})
+	}
+	writeJSON(w, 200, res.Result)
+}
+
+func (s *Server) handleSetSLA(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(405)
+		return
+	}
+
+	var body models.TeamSLA
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	var fe fieldErrors
+	fe.name("team_name", body.TeamName)
+	if body.SLAHours < 0 {
+		fe.add("sla_hours", "must not be negative")
+	}
+	if body.Policy == "" {
+		body.Policy = sla.PolicyNotify
+	}
+	if !sla.ValidPolicy(body.Policy) {
+		fe.add("policy", "must be one of notify, add_reviewer, reassign")
+	}
+	if fe.failed(w) {
+		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		writeError(w, 400, "INVALID", "bad request")
+	if err := s.guard.SetTeamSLA(r.Context(), body); err != nil {
+		if err == policy.ErrForbidden {
+			writeError(w, 403, "FORBIDDEN", "not allowed to manage this team")
+			return
+		}
+		if err == storage.ErrTeamNotFound {
+			writeError(w, 404, "NOT_FOUND", "team not found")
+			return
+		}
+		internalError(w, r, err)
 		return
 	}
 
-	if body.TeamName == "" || len(body.UserIDs) == 0 {
-		writeError(w, 400, "INVALID", "team_name and user_ids required")
+	writeJSON(w, 200, map[string]models.TeamSLA{"sla": body})
+}
+
+func (s *Server) handleSLABreaches(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(405)
 		return
 	}
 
-	err := s.store.BulkDeactivateUsers(context.Background(), body.TeamName, body.UserIDs)
+	team := r.URL.Query().Get("team_name")
+	if team != "" {
+		var fe fieldErrors
+		fe.name("team_name", team)
+		if fe.failed(w) {
+			return
+		}
+	}
+
+	breaches, err := s.sla.Breaches(r.Context(), team)
 	if err != nil {
-		writeError(w, 500, "ERROR", err.Error())
+		internalError(w, r, err)
 		return
 	}
 
-	writeJSON(w, 200, map[string]string{
-		"status": "ok",
+	writeJSON(w, 200, map[string]interface{}{
+		"breaches": breaches,
 	})
 }
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+
+	"Backend-trainee-assignment-autumn-2025/internal/auth"
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+	"Backend-trainee-assignment-autumn-2025/internal/policy"
+	"Backend-trainee-assignment-autumn-2025/internal/storage"
+)
+
+// userStore knows two members of backend, lets them change and opens their
+// pull requests. Calls the tests do not expect panic on the nil embedded
+// Store.
+type userStore struct {
+	policy.Store
+	users map[string]models.User
+}
+
+func (s userStore) GetUser(ctx context.Context, userID string) (models.User, error) {
+	u, ok := s.users[userID]
+	if !ok {
+		return u, storage.ErrUserNotFound
+	}
+	return u, nil
+}
+
+func (s userStore) SetUserActive(ctx context.Context, userID string, isActive bool) (models.User, error) {
+	u := s.users[userID]
+	u.IsActive = isActive
+	return u, nil
+}
+
+func (s userStore) SetUserNotifications(ctx context.Context, userID, email string, dailyDigest bool) (models.User, error) {
+	u := s.users[userID]
+	u.Email, u.DailyDigest = email, dailyDigest
+	return u, nil
+}
+
+func (s userStore) CreatePR(ctx context.Context, pr models.PullRequest) (models.PullRequest, error) {
+	pr.Status = "OPEN"
+	return pr, nil
+}
+
+func (s userStore) GetUserTeams(ctx context.Context, userIDs []string) (map[string]string, error) {
+	teams := make(map[string]string)
+	for _, id := range userIDs {
+		if u, ok := s.users[id]; ok {
+			teams[id] = u.TeamName
+		}
+	}
+	return teams, nil
+}
+
+func (s userStore) CreatePRs(ctx context.Context, prs []models.PullRequest) ([]storage.PRCreateResult, error) {
+	results := make([]storage.PRCreateResult, len(prs))
+	for i, pr := range prs {
+		results[i].PR, results[i].Err = s.CreatePR(ctx, pr)
+	}
+	return results, nil
+}
+
+// noRoles grants no roles in the database, so bearer tokens keep theirs.
+type noRoles struct{ auth.Store }
+
+func (noRoles) GetUserRoles(ctx context.Context, userID string) ([]string, error) {
+	return nil, nil
+}
+
+// jwtServer serves the routes with bearer tokens signed by the key it
+// returns.
+func jwtServer(t *testing.T, st policy.Store) (http.Handler, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwks, err := json.Marshal(map[string]interface{}{"keys": []map[string]string{{
+		"kty": "RSA", "kid": "k1", "use": "sig",
+		"n": b64(key.N.Bytes()), "e": b64(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwks, 0o600))
+	v, err := auth.NewJWTVerifier(context.Background(), auth.JWTConfig{JWKSFile: path})
+	require.NoError(t, err)
+
+	s := &Server{guard: policy.NewGuard(st), auth: auth.NewAuthenticator(noRoles{}, v)}
+	mux := http.NewServeMux()
+	s.routes(mux)
+	return mux, key
+}
+
+func bearer(t *testing.T, key *rsa.PrivateKey, userID string) string {
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"sub": userID,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	tok.Header["kid"] = "k1"
+	s, err := tok.SignedString(key)
+	require.NoError(t, err)
+	return "Bearer " + s
+}
+
+// A user without roles holds no admin:teams, yet may still change their own
+// activity and notifications, and only their own.
+func TestUserSettingsRoutes(t *testing.T) {
+	st := userStore{users: map[string]models.User{
+		"u1": {UserID: "u1", Username: "Alice", TeamName: "backend", IsActive: true},
+		"u2": {UserID: "u2", Username: "Bob", TeamName: "backend", IsActive: true},
+	}}
+	h, key := jwtServer(t, st)
+
+	for _, tt := range []struct {
+		path, body string
+	}{
+		{"/users/setIsActive", `{"user_id":"%s","is_active":false}`},
+		{"/users/setNotifications", `{"user_id":"%s","email":"a@example.com","daily_digest":true}`},
+	} {
+		for user, code := range map[string]int{"u1": http.StatusOK, "u2": http.StatusForbidden} {
+			r := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(strings.Replace(tt.body, "%s", user, 1)))
+			r.Header.Set("Authorization", bearer(t, key, "u1"))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			require.Equal(t, code, w.Code, "%s on %s: %s", tt.path, user, w.Body.String())
+		}
+	}
+}
+
+// A user without roles opens pull requests only as themselves.
+func TestCreatePRAuthor(t *testing.T) {
+	st := userStore{users: map[string]models.User{
+		"u1": {UserID: "u1", Username: "Alice", TeamName: "backend", IsActive: true},
+		"u2": {UserID: "u2", Username: "Bob", TeamName: "backend", IsActive: true},
+	}}
+	h, key := jwtServer(t, st)
+
+	for author, code := range map[string]int{"u1": http.StatusCreated, "u2": http.StatusForbidden} {
+		body := `{"pull_request_id":"pr-1","pull_request_name":"Add search","author_id":"` + author + `"}`
+		r := httptest.NewRequest(http.MethodPost, "/pullRequest/create", strings.NewReader(body))
+		r.Header.Set("Authorization", bearer(t, key, "u1"))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		require.Equal(t, code, w.Code, "author %s: %s", author, w.Body.String())
+	}
+}
+
+// A batch fails only the items whose author the caller may not act for.
+func TestBatchCreatePRAuthor(t *testing.T) {
+	st := userStore{users: map[string]models.User{
+		"u1": {UserID: "u1", Username: "Alice", TeamName: "backend", IsActive: true},
+		"u2": {UserID: "u2", Username: "Bob", TeamName: "backend", IsActive: true},
+	}}
+	h, key := jwtServer(t, st)
+
+	body := `[{"pull_request_id":"pr-1","pull_request_name":"Add search","author_id":"u2"},
+		{"pull_request_id":"pr-2","pull_request_name":"Fix search","author_id":"u1"}]`
+	r := httptest.NewRequest(http.MethodPost, "/pullRequest/batchCreate", strings.NewReader(body))
+	r.Header.Set("Authorization", bearer(t, key, "u1"))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp models.BatchPRResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Created, 1)
+	require.Equal(t, 1, resp.Created[0].Index)
+	require.Len(t, resp.Failed, 1)
+	require.Equal(t, 0, resp.Failed[0].Index)
+	require.Equal(t, "FORBIDDEN", resp.Failed[0].Error.Code)
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"Backend-trainee-assignment-autumn-2025/internal/logging"
+	"Backend-trainee-assignment-autumn-2025/internal/storage"
+)
+
+const readyTimeout = 2 * time.Second
+
+// WithShutdown makes /readyz report unavailable once ctx is done, so load
+// balancers stop routing to an instance that is draining.
+func WithShutdown(ctx context.Context) Option {
+	return func(s *Server) {
+		s.shutdown = ctx
+	}
+}
+
+func (s *Server) handleLivez(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, 200, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+	defer cancel()
+
+	ready := true
+	checks := map[string]interface{}{}
+
+	if s.shutdown != nil && s.shutdown.Err() != nil {
+		ready = false
+		checks["shutdown"] = "draining"
+	}
+
+	if err := s.store.Ping(ctx); err != nil {
+		ready = false
+		checks["database"] = "unavailable"
+		logging.FromContext(r.Context()).Warn("readyz: ping database", "error", err)
+	} else {
+		checks["database"] = "ok"
+
+		applied, err := s.store.AppliedSchemaVersion(ctx)
+		migrations := map[string]interface{}{"expected": storage.SchemaVersion, "applied": applied}
+		switch {
+		case err != nil:
+			ready = false
+			migrations["status"] = "unknown"
+			logging.FromContext(r.Context()).Warn("readyz: schema version", "error", err)
+		case applied < storage.SchemaVersion:
+			ready = false
+			migrations["status"] = "pending"
+		default:
+			migrations["status"] = "ok"
+		}
+		checks["migrations"] = migrations
+	}
+
+	code, status := 200, "ok"
+	if !ready {
+		code, status = 503, "unavailable"
+	}
+	writeJSON(w, code, map[string]interface{}{"status": status, "checks": checks})
+}
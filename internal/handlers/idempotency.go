@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"Backend-trainee-assignment-autumn-2025/internal/logging"
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+)
+
+const (
+	defaultIdempotencyTTL   = 24 * time.Hour
+	maxIdempotencyKeyLength = 255
+)
+
+// WithIdempotencyTTL sets how long responses to requests carrying an
+// Idempotency-Key are kept for replay.
+func WithIdempotencyTTL(d time.Duration) Option {
+	return func(s *Server) {
+		s.idempotencyTTL = d
+	}
+}
+
+// idempotent makes a mutating handler safe to retry. The first request with
+// an Idempotency-Key runs normally and its response is stored; a repeat with
+// the same key and the same body gets that response back instead of running
+// again, and a repeat with a different body gets 409. Keys are per caller.
+// Server errors are not stored, so the request can be retried; neither are
+// dry runs, which leave the key free.
+func (s *Server) idempotent(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" || r.Method != http.MethodPost {
+			h(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength || !printableASCII(key) {
+			writeInvalid(w, []models.FieldError{{Field: "Idempotency-Key",
+				Reason: fmt.Sprintf("must be 1 to %d printable ASCII characters", maxIdempotencyKeyLength)}})
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeError(w, http.StatusRequestEntityTooLarge, "INVALID",
+					fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit))
+				return
+			}
+			writeInvalid(w, []models.FieldError{{Reason: "read body: " + err.Error()}})
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if dryRun(body) {
+			// A preview changes nothing, so there is nothing to protect,
+			// and it must not hold the key its real run will use.
+			h(w, r)
+			return
+		}
+		sum := sha256.Sum256(append([]byte(r.URL.Path+"\n"), body...))
+		hash := hex.EncodeToString(sum[:])
+
+		prev, err := s.store.ReserveIdempotencyKey(r.Context(), key, hash, s.idempotencyTTL)
+		if err != nil {
+			internalError(w, r, err)
+			return
+		}
+		switch {
+		case prev == nil:
+		case prev.RequestHash != hash:
+			writeError(w, 409, "IDEMPOTENCY_CONFLICT", "Idempotency-Key was already used for a different request")
+			return
+		case !prev.Done:
+			writeError(w, 409, "IDEMPOTENCY_CONFLICT", "a request with this Idempotency-Key is still in progress")
+			return
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(prev.StatusCode)
+			_, _ = w.Write(prev.Response)
+			return
+		}
+
+		// The response is already sent; finish bookkeeping even if the
+		// client has gone away.
+		ctx := context.WithoutCancel(r.Context())
+		completed := false
+		defer func() {
+			// Also runs when h panics, so the key does not stay "in
+			// progress" until it expires.
+			if completed {
+				return
+			}
+			if err := s.store.ReleaseIdempotencyKey(ctx, key); err != nil {
+				logging.FromContext(ctx).Error("release idempotency key", "err", err)
+			}
+		}()
+
+		rec := &responseCapture{ResponseWriter: w, code: http.StatusOK}
+		h(rec, r)
+
+		if rec.code < 500 {
+			if err := s.store.CompleteIdempotencyKey(ctx, key, rec.code, rec.body.Bytes()); err != nil {
+				logging.FromContext(ctx).Error("store idempotent response", "err", err)
+			}
+			completed = true
+		}
+	}
+}
+
+// dryRun reports whether a JSON request body asks for "dry_run": true.
+func dryRun(body []byte) bool {
+	var v struct {
+		DryRun bool `json:"dry_run"`
+	}
+	return json.Unmarshal(body, &v) == nil && v.DryRun
+}
+
+func printableASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// responseCapture passes the response through while keeping a copy.
+type responseCapture struct {
+	http.ResponseWriter
+	code        int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (c *responseCapture) WriteHeader(code int) {
+	if !c.wroteHeader {
+		c.code, c.wroteHeader = code, true
+	}
+	c.ResponseWriter.WriteHeader(code)
+}
+
+func (c *responseCapture) Write(p []byte) (int, error) {
+	c.wroteHeader = true
+	c.body.Write(p)
+	return c.ResponseWriter.Write(p)
+}
+
+func (c *responseCapture) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
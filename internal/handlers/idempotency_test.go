@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Requests the middleware does not apply to never reach the store, which is
+// nil here.
+func TestIdempotentBypass(t *testing.T) {
+	s := &Server{}
+	called := 0
+	h := s.idempotent(func(w http.ResponseWriter, r *http.Request) {
+		called++
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	for _, r := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/pullRequest/create", strings.NewReader(`{}`)),
+		func() *http.Request {
+			r := httptest.NewRequest(http.MethodGet, "/pullRequest/create", nil)
+			r.Header.Set("Idempotency-Key", "k1")
+			return r
+		}(),
+		func() *http.Request {
+			r := httptest.NewRequest(http.MethodPost, "/pullRequest/reassign", strings.NewReader(`{"dry_run":true}`))
+			r.Header.Set("Idempotency-Key", "k1")
+			return r
+		}(),
+	} {
+		w := httptest.NewRecorder()
+		h(w, r)
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+	require.Equal(t, 3, called)
+}
+
+func TestIdempotentRejectsBadKey(t *testing.T) {
+	s := &Server{}
+	h := s.idempotent(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler called")
+	})
+
+	for _, key := range []string{"bad\tkey", "ключ", strings.Repeat("k", maxIdempotencyKeyLength+1)} {
+		r := httptest.NewRequest(http.MethodPost, "/pullRequest/create", strings.NewReader(`{}`))
+		r.Header.Set("Idempotency-Key", key)
+		w := httptest.NewRecorder()
+		h(w, r)
+		require.Equal(t, http.StatusBadRequest, w.Code, key)
+		require.Contains(t, w.Body.String(), `"field":"Idempotency-Key"`)
+	}
+}
+
+func TestResponseCapture(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := &responseCapture{ResponseWriter: w, code: http.StatusOK}
+	c.WriteHeader(http.StatusConflict)
+	c.WriteHeader(http.StatusOK)
+	_, _ = c.Write([]byte(`{"a":1}`))
+
+	require.Equal(t, http.StatusConflict, c.code)
+	require.Equal(t, `{"a":1}`, c.body.String())
+	require.Equal(t, `{"a":1}`, w.Body.String())
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"Backend-trainee-assignment-autumn-2025/internal/logging"
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+	"Backend-trainee-assignment-autumn-2025/internal/policy"
+	"Backend-trainee-assignment-autumn-2025/internal/sla"
+	"Backend-trainee-assignment-autumn-2025/internal/storage"
+)
+
+// handleSnapshot streams the archive of the whole database. Like the
+// exports, the 200 goes out with the first bytes; a failure after that
+// aborts the response so a truncated archive is never mistaken for a whole
+// one.
+func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(405)
+		return
+	}
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	out := &snapshotWriter{w: w}
+	err := s.guard.WriteSnapshot(r.Context(), out)
+	switch {
+	case err == nil:
+	case out.started:
+		logging.FromContext(r.Context()).Error("snapshot failed", "error", err)
+		panic(http.ErrAbortHandler)
+	case err == policy.ErrForbidden:
+		writeError(w, 403, "FORBIDDEN", "only admins can export snapshots")
+	default:
+		internalError(w, r, err)
+	}
+}
+
+type snapshotWriter struct {
+	w       http.ResponseWriter
+	started bool
+}
+
+func (o *snapshotWriter) Write(p []byte) (int, error) {
+	if !o.started {
+		o.started = true
+		o.w.Header().Set("Content-Type", "application/json")
+		o.w.Header().Set("Content-Disposition", `attachment; filename="snapshot.json"`)
+		o.w.WriteHeader(http.StatusOK)
+	}
+	return o.w.Write(p)
+}
+
+func (s *Server) handleRestoreSnapshot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(405)
+		return
+	}
+	mode := r.URL.Query().Get("mode")
+	switch mode {
+	case "":
+		mode = storage.ConflictFail
+	case storage.ConflictFail, storage.ConflictSkip, storage.ConflictOverwrite:
+	default:
+		writeInvalid(w, []models.FieldError{{Field: "mode", Reason: "must be fail, skip or overwrite"}})
+		return
+	}
+	// Restoring a large archive may outlast the server's timeouts.
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	var snap models.Snapshot
+	if !decodeBody(w, r, &snap) {
+		return
+	}
+	var fe fieldErrors
+	validateSnapshot(&fe, &snap)
+	if fe.failed(w) {
+		return
+	}
+
+	res, err := s.guard.RestoreSnapshot(r.Context(), snap, mode)
+	var se *storage.SnapshotError
+	switch {
+	case err == nil:
+		writeJSON(w, 200, res)
+	case err == policy.ErrForbidden:
+		writeError(w, 403, "FORBIDDEN", "only admins can restore snapshots")
+	case errors.As(err, &se) && se.Conflict:
+		var e models.ErrorResponse
+		e.Error.Code = "SNAPSHOT_CONFLICT"
+		e.Error.Message = fmt.Sprintf("%d rows of the archive already exist; use mode skip or overwrite", len(se.Problems))
+		e.Error.Details = se.Problems
+		writeJSON(w, 409, e)
+	case se != nil:
+		writeInvalid(w, se.Problems)
+	default:
+		internalError(w, r, err)
+	}
+}
+
+// validateSnapshot checks every row of snap on its own and fills in
+// defaults; references between rows are checked by the store, which also
+// sees what the database already holds.
+func validateSnapshot(fe *fieldErrors, snap *models.Snapshot) {
+	if snap.Version != storage.SnapshotVersion {
+		fe.add("version", fmt.Sprintf("unsupported snapshot version %d, want %d", snap.Version, storage.SnapshotVersion))
+		return
+	}
+	for i := range snap.Teams {
+		t := &snap.Teams[i]
+		p := fmt.Sprintf("teams.%d.", i)
+		fe.name(p+"team_name", t.TeamName)
+		if t.SLAHours != nil && *t.SLAHours <= 0 {
+			fe.add(p+"sla_hours", "must be positive")
+		}
+		if t.SLAPolicy == "" {
+			t.SLAPolicy = sla.PolicyNotify
+		}
+		if !sla.ValidPolicy(t.SLAPolicy) {
+			fe.add(p+"sla_policy", "unknown policy "+t.SLAPolicy)
+		}
+	}
+	for i, u := range snap.Users {
+		p := fmt.Sprintf("users.%d.", i)
+		fe.id(p+"user_id", u.UserID)
+		fe.name(p+"username", u.Username)
+		fe.name(p+"team_name", u.TeamName)
+		if msg := validateNotifications(u.Email, u.DailyDigest); msg != "" {
+			fe.add(p+"email", msg)
+		}
+		for j, role := range u.Roles {
+			if !policy.ValidRole(role) {
+				fe.add(fmt.Sprintf("%sroles.%d", p, j), "unknown role "+role)
+			}
+		}
+	}
+	for i, pr := range snap.PullRequests {
+		p := fmt.Sprintf("pull_requests.%d.", i)
+		fe.id(p+"pull_request_id", pr.PullRequestID)
+		fe.name(p+"pull_request_name", pr.PullRequestName)
+		fe.id(p+"author_id", pr.AuthorID)
+		switch pr.Status {
+		case "OPEN", "MERGED", "CLOSED":
+		default:
+			fe.add(p+"status", "must be one of OPEN, MERGED, CLOSED")
+		}
+		if pr.CreatedAt.IsZero() {
+			fe.add(p+"created_at", "required")
+		}
+	}
+	for i, rv := range snap.Reviewers {
+		p := fmt.Sprintf("reviewers.%d.", i)
+		fe.id(p+"pull_request_id", rv.PullRequestID)
+		fe.id(p+"user_id", rv.UserID)
+		if rv.AssignedAt.IsZero() {
+			fe.add(p+"assigned_at", "required")
+		}
+	}
+	for i, e := range snap.History {
+		p := fmt.Sprintf("history.%d.", i)
+		fe.id(p+"pull_request_id", e.PullRequestID)
+		fe.id(p+"user_id", e.UserID)
+		if e.PreviousUserID != "" {
+			fe.id(p+"previous_user_id", e.PreviousUserID)
+		}
+		switch e.Event {
+		case storage.EventAssigned, storage.EventUnassigned, storage.EventReplaced:
+		default:
+			fe.add(p+"event", "unknown event "+e.Event)
+		}
+		switch e.Reason {
+		case storage.ReasonInitial, storage.ReasonManualReassign, storage.ReasonDeactivation, storage.ReasonSLA, storage.ReasonCapacity:
+		default:
+			fe.add(p+"reason", "unknown reason "+e.Reason)
+		}
+		if e.Actor == "" {
+			fe.add(p+"actor", "required")
+		}
+		if e.CreatedAt.IsZero() {
+			fe.add(p+"created_at", "required")
+		}
+	}
+}
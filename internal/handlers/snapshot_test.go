@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+)
+
+func TestValidateSnapshot(t *testing.T) {
+	now := time.Now()
+	snap := models.Snapshot{
+		Version:      1,
+		Teams:        []models.SnapshotTeam{{TeamName: "backend"}},
+		Users:        []models.SnapshotUser{{UserID: "u1", Username: "Alice", TeamName: "backend", Roles: []string{"owner"}}},
+		PullRequests: []models.SnapshotPR{{PullRequestID: "pr-1", PullRequestName: "Add search", AuthorID: "u1", Status: "DRAFT", CreatedAt: now}},
+		Reviewers:    []models.SnapshotReviewer{{PullRequestID: "pr-1", UserID: "u2"}},
+		History: []models.SnapshotEvent{{PullRequestID: "pr-1", Event: "assigned", UserID: "u2", Reason: "initial",
+			Actor: "system", CreatedAt: now}},
+	}
+	var fe fieldErrors
+	validateSnapshot(&fe, &snap)
+	require.Equal(t, "notify", snap.Teams[0].SLAPolicy)
+	fields := make([]string, 0, len(fe))
+	for _, e := range fe {
+		fields = append(fields, e.Field)
+	}
+	require.Equal(t, []string{"users.0.roles.0", "pull_requests.0.status", "reviewers.0.assigned_at"}, fields)
+
+	fe = nil
+	validateSnapshot(&fe, &models.Snapshot{Version: 2})
+	require.Len(t, fe, 1)
+	require.Equal(t, "version", fe[0].Field)
+}
+
+func TestRestoreSnapshotMode(t *testing.T) {
+	s := &Server{}
+	r := httptest.NewRequest(http.MethodPost, "/admin/snapshot/restore?mode=replace", strings.NewReader(`{"version":1}`))
+	w := httptest.NewRecorder()
+	s.handleRestoreSnapshot(w, r)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), `"field":"mode"`)
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Bad filters are rejected before the store is queried.
+func TestStatsParams(t *testing.T) {
+	s := &Server{}
+	r := httptest.NewRequest(http.MethodGet, "/stats?team_name=&from=yesterday&to=2025-10-01T00:00:00Z", nil)
+	w := httptest.NewRecorder()
+	s.handleStats(w, r)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), `"field":"from"`)
+}
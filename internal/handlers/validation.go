@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+)
+
+const (
+	// maxBodyBytes caps every request body; larger ones get 413.
+	maxBodyBytes = 1 << 20
+	// maxImportBytes caps streamed imports instead; each of their lines is
+	// still held to maxBodyBytes.
+	maxImportBytes = 256 << 20
+
+	maxIDLength   = 64
+	maxNameLength = 256
+)
+
+// idPattern is the charset of user, pull request and API key IDs.
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]+$`)
+
+// fieldErrors collects the problems of one request so the client gets all
+// of them in a single 400.
+type fieldErrors []models.FieldError
+
+func (fe *fieldErrors) add(field, reason string) {
+	*fe = append(*fe, models.FieldError{Field: field, Reason: reason})
+}
+
+// id checks an identifier: non-empty, bounded and from idPattern.
+func (fe *fieldErrors) id(field, v string) {
+	switch {
+	case v == "":
+		fe.add(field, "required")
+	case len(v) > maxIDLength:
+		fe.add(field, fmt.Sprintf("must be at most %d characters", maxIDLength))
+	case !idPattern.MatchString(v):
+		fe.add(field, "may contain only letters, digits and . _ : -")
+	}
+}
+
+// name checks free-form text such as team names, usernames and PR titles.
+func (fe *fieldErrors) name(field, v string) {
+	switch {
+	case strings.TrimSpace(v) == "":
+		fe.add(field, "required")
+	case utf8.RuneCountInString(v) > maxNameLength:
+		fe.add(field, fmt.Sprintf("must be at most %d characters", maxNameLength))
+	case !utf8.ValidString(v) || strings.IndexFunc(v, unicode.IsControl) >= 0:
+		fe.add(field, "must be valid UTF-8 without control characters")
+	}
+}
+
+// timeRange parses the optional RFC3339 from and to query parameters.
+func (fe *fieldErrors) timeRange(q url.Values) (from, to *time.Time) {
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"from", &from}, {"to", &to}} {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			fe.add(p.name, "must be an RFC3339 timestamp")
+			continue
+		}
+		*p.dst = &t
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		fe.add("to", "must be after from")
+	}
+	return from, to
+}
+
+// failed writes a 400 listing fe, if there is anything to list, and reports
+// whether it did.
+func (fe fieldErrors) failed(w http.ResponseWriter) bool {
+	if len(fe) == 0 {
+		return false
+	}
+	writeInvalid(w, fe)
+	return true
+}
+
+// message summarises fe by its first entry.
+func (fe fieldErrors) message() string {
+	d := fe[0]
+	if d.Field != "" {
+		return d.Field + ": " + d.Reason
+	}
+	return d.Reason
+}
+
+func writeInvalid(w http.ResponseWriter, details []models.FieldError) {
+	var e models.ErrorResponse
+	e.Error.Code = "INVALID"
+	e.Error.Message = fieldErrors(details).message()
+	e.Error.Details = details
+	writeJSON(w, 400, e)
+}
+
+// decodeBody decodes the JSON request body into v, rejecting unknown fields
+// and trailing data. On failure it writes the error response and returns
+// false.
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err == nil && dec.More() {
+		err = errors.New("unexpected data after the JSON value")
+	}
+	if err == nil {
+		return true
+	}
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, http.StatusRequestEntityTooLarge, "INVALID",
+			fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit))
+		return false
+	}
+	writeInvalid(w, []models.FieldError{bodyError(err)})
+	return false
+}
+
+func bodyError(err error) models.FieldError {
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.Is(err, io.EOF):
+		return models.FieldError{Reason: "request body required"}
+	case errors.As(err, &typeErr):
+		return models.FieldError{Field: typeErr.Field, Reason: "must be " + jsonType(typeErr.Type)}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return models.FieldError{Reason: "malformed JSON: " + err.Error()}
+	}
+	// encoding/json has no type for unknown fields.
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return models.FieldError{Field: strings.Trim(name, `"`), Reason: "unknown field"}
+	}
+	return models.FieldError{Reason: err.Error()}
+}
+
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	}
+	return "a number"
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+)
+
+func TestFieldErrorsID(t *testing.T) {
+	tests := []struct {
+		value  string
+		reason string
+	}{
+		{"u1", ""},
+		{"pr-1001_v2.final:x", ""},
+		{"", "required"},
+		{strings.Repeat("a", maxIDLength+1), "must be at most 64 characters"},
+		{"u 1", "may contain only letters, digits and . _ : -"},
+		{"юзер", "may contain only letters, digits and . _ : -"},
+	}
+	for _, tt := range tests {
+		var fe fieldErrors
+		fe.id("user_id", tt.value)
+		if tt.reason == "" {
+			require.Empty(t, fe, tt.value)
+			continue
+		}
+		require.Equal(t, fieldErrors{{Field: "user_id", Reason: tt.reason}}, fe, tt.value)
+	}
+}
+
+func TestFieldErrorsName(t *testing.T) {
+	var fe fieldErrors
+	fe.name("team_name", "Платежи")
+	require.Empty(t, fe)
+
+	fe.name("team_name", "  ")
+	fe.name("username", "bob\x00")
+	fe.name("pull_request_name", strings.Repeat("я", maxNameLength+1))
+	require.Equal(t, []string{"team_name", "username", "pull_request_name"},
+		[]string{fe[0].Field, fe[1].Field, fe[2].Field})
+}
+
+func decodeRequest(t *testing.T, body string) (*httptest.ResponseRecorder, bool) {
+	t.Helper()
+	var v struct {
+		ID    string   `json:"pull_request_id"`
+		Roles []string `json:"roles"`
+	}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Body = http.MaxBytesReader(w, r.Body, 64)
+	return w, decodeBody(w, r, &v)
+}
+
+func TestDecodeBody(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		field  string
+	}{
+		{"valid", `{"pull_request_id":"pr-1","roles":["admin"]}`, 200, ""},
+		{"unknown field", `{"pull_request_id":"pr-1","old_reviewer_id":"u2"}`, 400, "old_reviewer_id"},
+		{"wrong type", `{"roles":"admin"}`, 400, "roles"},
+		{"empty", ``, 400, ""},
+		{"malformed", `{"pull_request_id":`, 400, ""},
+		{"trailing data", `{"pull_request_id":"pr-1"} {}`, 400, ""},
+		{"too large", `{"pull_request_id":"` + strings.Repeat("x", 100) + `"}`, 413, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, ok := decodeRequest(t, tt.body)
+			if tt.status == 200 {
+				require.True(t, ok)
+				return
+			}
+			require.False(t, ok)
+			require.Equal(t, tt.status, w.Code)
+
+			var er models.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &er))
+			require.Equal(t, "INVALID", er.Error.Code)
+			if tt.status == 400 {
+				require.Len(t, er.Error.Details, 1)
+				require.Equal(t, tt.field, er.Error.Details[0].Field)
+			}
+		})
+	}
+}
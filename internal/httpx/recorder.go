@@ -0,0 +1,39 @@
+// Package httpx holds small helpers shared by the HTTP middlewares.
+package httpx
+
+import "net/http"
+
+// StatusRecorder remembers the status code written through it so
+// middlewares can report it after the handler returns.
+type StatusRecorder struct {
+	http.ResponseWriter
+	Code        int
+	Bytes       int
+	wroteHeader bool
+}
+
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	if rec, ok := w.(*StatusRecorder); ok {
+		return rec
+	}
+	return &StatusRecorder{ResponseWriter: w, Code: http.StatusOK}
+}
+
+func (r *StatusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.Code = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *StatusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.Bytes += n
+	return n, err
+}
+
+func (r *StatusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+
+	"Backend-trainee-assignment-autumn-2025/internal/notify"
+	"Backend-trainee-assignment-autumn-2025/internal/storage"
+)
+
+type Digest struct {
+	store    *storage.Store
+	notifier *notify.Notifier
+}
+
+func NewDigest(st *storage.Store, n *notify.Notifier) *Digest {
+	return &Digest{store: st, notifier: n}
+}
+
+// Run sends every subscribed user the list of OPEN pull requests they are
+// reviewing. Users with nothing to review get no email; a user whose list
+// cannot be read is logged and skipped like a failed send.
+func (d *Digest) Run(ctx context.Context) error {
+	users, err := d.store.GetDigestSubscribers(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, u := range users {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		open, err := d.store.GetPRsForReviewer(ctx, u.UserID, storage.PRFilter{Status: "OPEN"})
+		if err != nil {
+			slog.ErrorContext(ctx, "load digest", "user_id", u.UserID, "error", err)
+			continue
+		}
+		if len(open) == 0 {
+			continue
+		}
+
+		if err := d.notifier.Send(ctx, notify.DigestMessage(u, open)); err != nil {
+			slog.ErrorContext(ctx, "send digest", "user_id", u.UserID, "error", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+
+	"Backend-trainee-assignment-autumn-2025/internal/storage"
+)
+
+// PurgeIdempotencyKeys returns a job that deletes expired Idempotency-Key
+// records.
+func PurgeIdempotencyKeys(st *storage.Store) func(context.Context) error {
+	return func(ctx context.Context) error {
+		n, err := st.PurgeIdempotencyKeys(ctx)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			slog.Info("purged expired idempotency keys", "count", n)
+		}
+		return nil
+	}
+}
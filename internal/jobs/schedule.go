@@ -0,0 +1,63 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"Backend-trainee-assignment-autumn-2025/internal/auth"
+)
+
+// RunDaily calls fn once a day at the given UTC time of day until ctx is
+// cancelled.
+func RunDaily(ctx context.Context, at time.Duration, name string, fn func(context.Context) error) {
+	ctx = auth.WithPrincipal(ctx, auth.System(name))
+	for {
+		wait := time.Until(nextDaily(time.Now().UTC(), at))
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+		if err := fn(ctx); err != nil {
+			slog.ErrorContext(ctx, "job failed", "job", name, "error", err)
+		}
+	}
+}
+
+func nextDaily(now time.Time, at time.Duration) time.Time {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	next := day.Add(at)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// ParseTimeOfDay parses "HH:MM" into an offset from midnight.
+func ParseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// RunEvery calls fn every interval until ctx is cancelled.
+func RunEvery(ctx context.Context, interval time.Duration, name string, fn func(context.Context) error) {
+	ctx = auth.WithPrincipal(ctx, auth.System(name))
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		if err := fn(ctx); err != nil {
+			slog.ErrorContext(ctx, "job failed", "job", name, "error", err)
+		}
+	}
+}
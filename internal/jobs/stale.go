@@ -0,0 +1,72 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+	"Backend-trainee-assignment-autumn-2025/internal/notify"
+	"Backend-trainee-assignment-autumn-2025/internal/storage"
+)
+
+type Stale struct {
+	store    *storage.Store
+	notifier *notify.Notifier
+	// After is how long an OPEN PR may go without activity before it is
+	// flagged stale.
+	After time.Duration
+	// CloseAfter is the grace period between flagging and closing a stale
+	// PR. Zero disables auto-close.
+	CloseAfter time.Duration
+}
+
+func NewStale(st *storage.Store, n *notify.Notifier, after, closeAfter time.Duration) *Stale {
+	return &Stale{store: st, notifier: n, After: after, CloseAfter: closeAfter}
+}
+
+func (j *Stale) Run(ctx context.Context) error {
+	now := time.Now()
+
+	flagged, err := j.store.MarkStalePRs(ctx, now.Add(-j.After))
+	if err != nil {
+		return err
+	}
+	for _, pr := range flagged {
+		j.notifyAuthor(ctx, pr, func(u models.User) notify.Message {
+			return notify.StaleMessage(pr, u, j.CloseAfter)
+		})
+	}
+
+	if j.CloseAfter <= 0 {
+		return nil
+	}
+
+	closed, err := j.store.CloseStalePRs(ctx, now.Add(-j.CloseAfter))
+	if err != nil {
+		return err
+	}
+	for _, pr := range closed {
+		j.notifyAuthor(ctx, pr, func(u models.User) notify.Message {
+			return notify.StaleClosedMessage(pr, u)
+		})
+	}
+	return nil
+}
+
+func (j *Stale) notifyAuthor(ctx context.Context, pr models.PullRequestShort, msg func(models.User) notify.Message) {
+	if j.notifier == nil {
+		return
+	}
+	u, err := j.store.GetUser(ctx, pr.AuthorID)
+	if err != nil {
+		slog.ErrorContext(ctx, "stale: lookup author", "user_id", pr.AuthorID, "error", err)
+		return
+	}
+	if u.Email == "" {
+		return
+	}
+	if err := j.notifier.Send(ctx, msg(u)); err != nil {
+		slog.ErrorContext(ctx, "stale: send email", "pull_request_id", pr.PullRequestID, "error", err)
+	}
+}
@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func captureDefault(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(New(&buf, slog.LevelDebug))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
+
+func TestMiddlewarePropagatesRequestID(t *testing.T) {
+	buf := captureDefault(t)
+
+	var seen string
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestID(r.Context())
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/team/get", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	require.Equal(t, "abc-123", seen)
+	require.Equal(t, "abc-123", w.Header().Get(RequestIDHeader))
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	require.Equal(t, "request", line["msg"])
+	require.Equal(t, "abc-123", line["request_id"])
+	require.Equal(t, "/team/get", line["path"])
+	require.Equal(t, float64(http.StatusTeapot), line["status"])
+}
+
+func TestMiddlewareGeneratesRequestID(t *testing.T) {
+	captureDefault(t)
+
+	h := Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	for _, incoming := range []string{"", "bad id", strings.Repeat("x", maxRequestIDLen+1)} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if incoming != "" {
+			req.Header.Set(RequestIDHeader, incoming)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		id := w.Header().Get(RequestIDHeader)
+		require.Len(t, id, 32, "incoming %q", incoming)
+		require.NotEqual(t, incoming, id)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"", slog.LevelInfo, false},
+		{"DEBUG", slog.LevelDebug, false},
+		{"warn", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"verbose", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.in)
+		if tt.wantErr {
+			require.Error(t, err, tt.in)
+			continue
+		}
+		require.NoError(t, err, tt.in)
+		require.Equal(t, tt.want, got, tt.in)
+	}
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"Backend-trainee-assignment-autumn-2025/internal/httpx"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLen = 128
+
+// Middleware assigns every request an ID, taken from X-Request-ID when the
+// client sent a usable one and generated otherwise, echoes it in the
+// response and writes an access log line when the request completes.
+func Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		rec := httpx.NewStatusRecorder(w)
+		ctx := WithRequestID(r.Context(), id)
+		h.ServeHTTP(rec, r.WithContext(ctx))
+
+		level := slog.LevelInfo
+		if rec.Code >= 500 {
+			level = slog.LevelError
+		}
+		FromContext(ctx).LogAttrs(ctx, level, "request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.Code),
+			slog.Int("bytes", rec.Bytes),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("remote_addr", r.RemoteAddr),
+		)
+	})
+}
+
+// validRequestID accepts non-empty printable ASCII IDs of bounded length so
+// client-supplied values cannot inject into logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
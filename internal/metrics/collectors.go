@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	poolAcquiredDesc = prometheus.NewDesc(namespace+"_db_pool_acquired_conns",
+		"Connections currently checked out of the pool.", nil, nil)
+	poolIdleDesc = prometheus.NewDesc(namespace+"_db_pool_idle_conns",
+		"Idle connections in the pool.", nil, nil)
+	poolTotalDesc = prometheus.NewDesc(namespace+"_db_pool_total_conns",
+		"Total connections in the pool.", nil, nil)
+	poolMaxDesc = prometheus.NewDesc(namespace+"_db_pool_max_conns",
+		"Maximum size of the pool.", nil, nil)
+	poolAcquireCountDesc = prometheus.NewDesc(namespace+"_db_pool_acquires_total",
+		"Successful connection acquisitions.", nil, nil)
+	poolEmptyAcquireDesc = prometheus.NewDesc(namespace+"_db_pool_empty_acquires_total",
+		"Acquisitions that had to wait because the pool was empty.", nil, nil)
+	poolAcquireWaitDesc = prometheus.NewDesc(namespace+"_db_pool_acquire_wait_seconds_total",
+		"Total time spent waiting to acquire a connection.", nil, nil)
+)
+
+// poolCollector reads pgxpool statistics at scrape time.
+type poolCollector struct {
+	pool *pgxpool.Pool
+}
+
+// RegisterPool exports the statistics of pool.
+func RegisterPool(pool *pgxpool.Pool) {
+	Registry.MustRegister(poolCollector{pool: pool})
+}
+
+func (c poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolAcquiredDesc
+	ch <- poolIdleDesc
+	ch <- poolTotalDesc
+	ch <- poolMaxDesc
+	ch <- poolAcquireCountDesc
+	ch <- poolEmptyAcquireDesc
+	ch <- poolAcquireWaitDesc
+}
+
+func (c poolCollector) Collect(ch chan<- prometheus.Metric) {
+	st := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(poolAcquiredDesc, prometheus.GaugeValue, float64(st.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(poolIdleDesc, prometheus.GaugeValue, float64(st.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(poolTotalDesc, prometheus.GaugeValue, float64(st.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(poolMaxDesc, prometheus.GaugeValue, float64(st.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(poolAcquireCountDesc, prometheus.CounterValue, float64(st.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolEmptyAcquireDesc, prometheus.CounterValue, float64(st.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolAcquireWaitDesc, prometheus.CounterValue, st.AcquireDuration().Seconds())
+}
+
+var openReviewsDesc = prometheus.NewDesc(namespace+"_open_reviews",
+	"Reviewer assignments on OPEN pull requests, by reviewer team.", []string{"team"}, nil)
+
+// OpenReviewSource counts current review assignments per team.
+type OpenReviewSource interface {
+	OpenReviewsByTeam(ctx context.Context) (map[string]int, error)
+}
+
+type openReviewsCollector struct {
+	src OpenReviewSource
+}
+
+// RegisterOpenReviews exports the open reviews gauge, queried from src on
+// every scrape.
+func RegisterOpenReviews(src OpenReviewSource) {
+	Registry.MustRegister(openReviewsCollector{src: src})
+}
+
+func (c openReviewsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- openReviewsDesc
+}
+
+func (c openReviewsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	counts, err := c.src.OpenReviewsByTeam(ctx)
+	if err != nil {
+		slog.Error("metrics: count open reviews", "error", err)
+		return
+	}
+	for team, n := range counts {
+		ch <- prometheus.MustNewConstMetric(openReviewsDesc, prometheus.GaugeValue, float64(n), team)
+	}
+}
@@ -0,0 +1,96 @@
+// Package metrics exposes Prometheus metrics for the HTTP API, the database
+// pool and the reviewer assignment domain.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"Backend-trainee-assignment-autumn-2025/internal/httpx"
+)
+
+const namespace = "pr_reviewer"
+
+// Registry holds every metric of the service. A private registry keeps tests
+// and multiple servers in one process from clashing on the global one.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route, method and status code.",
+	}, []string{"route", "method", "code"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	prsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pull_requests_created_total",
+		Help:      "Pull requests created.",
+	})
+
+	prsMerged = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pull_requests_merged_total",
+		Help:      "Pull requests merged.",
+	})
+
+	reassignments = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reviewer_reassignments_total",
+		Help:      "Reviewers replaced on a pull request, by reason.",
+	}, []string{"reason"})
+
+	noCandidate = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "no_candidate_errors_total",
+		Help:      "Assignments that failed with NO_CANDIDATE, by operation.",
+	}, []string{"operation"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration,
+		prsCreated, prsMerged, reassignments, noCandidate,
+	)
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// Instrument records count and latency of requests to h under the given
+// route label. The route is the registered pattern, not the raw path, to keep
+// label cardinality bounded.
+func Instrument(route string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := httpx.NewStatusRecorder(w)
+		h.ServeHTTP(rec, r)
+
+		httpDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+		httpRequests.WithLabelValues(route, r.Method, strconv.Itoa(rec.Code)).Inc()
+	})
+}
+
+func PRCreated() { prsCreated.Inc() }
+
+func PRMerged() { prsMerged.Inc() }
+
+func Reassigned(reason string) { reassignments.WithLabelValues(reason).Inc() }
+
+func NoCandidate(operation string) { noCandidate.WithLabelValues(operation).Inc() }
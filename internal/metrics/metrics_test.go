@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentRecordsRouteAndCode(t *testing.T) {
+	h := Instrument("/test/route", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	before := testutil.ToFloat64(httpRequests.WithLabelValues("/test/route", "POST", "409"))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/test/route?x=1", nil))
+
+	require.Equal(t, before+1, testutil.ToFloat64(httpRequests.WithLabelValues("/test/route", "POST", "409")))
+	require.Equal(t, 0.0, testutil.ToFloat64(httpRequests.WithLabelValues("/test/route", "POST", "500")))
+}
+
+func TestInstrumentDefaultsTo200(t *testing.T) {
+	h := Instrument("/test/ok", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test/ok", nil))
+
+	require.Equal(t, 1.0, testutil.ToFloat64(httpRequests.WithLabelValues("/test/ok", "GET", "200")))
+}
+
+type fakeReviews map[string]int
+
+func (f fakeReviews) OpenReviewsByTeam(context.Context) (map[string]int, error) {
+	return f, nil
+}
+
+func TestOpenReviewsCollector(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(openReviewsCollector{src: fakeReviews{"backend": 3, "frontend": 1}})
+
+	expected := `
+# HELP pr_reviewer_open_reviews Reviewer assignments on OPEN pull requests, by reviewer team.
+# TYPE pr_reviewer_open_reviews gauge
+pr_reviewer_open_reviews{team="backend"} 3
+pr_reviewer_open_reviews{team="frontend"} 1
+`
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "pr_reviewer_open_reviews"))
+}
@@ -1,11 +1,16 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type TeamMember struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	IsActive bool   `json:"is_active"`
+	UserID      string `json:"user_id"`
+	Username    string `json:"username"`
+	IsActive    bool   `json:"is_active"`
+	Email       string `json:"email,omitempty"`
+	DailyDigest bool   `json:"daily_digest,omitempty"`
 }
 
 type Team struct {
@@ -14,10 +19,12 @@ type Team struct {
 }
 
 type User struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	UserID      string `json:"user_id"`
+	Username    string `json:"username"`
+	TeamName    string `json:"team_name"`
+	IsActive    bool   `json:"is_active"`
+	Email       string `json:"email,omitempty"`
+	DailyDigest bool   `json:"daily_digest,omitempty"`
 }
 
 type PullRequest struct {
@@ -28,6 +35,9 @@ type PullRequest struct {
 	AssignedReviewers []string   `json:"assigned_reviewers"`
 	CreatedAt         *time.Time `json:"created_at,omitempty"`
 	MergedAt          *time.Time `json:"merged_at,omitempty"`
+	IsStale           bool       `json:"is_stale"`
+	StaleSince        *time.Time `json:"stale_since,omitempty"`
+	ClosedAt          *time.Time `json:"closed_at,omitempty"`
 }
 
 type PullRequestShort struct {
@@ -35,11 +45,307 @@ type PullRequestShort struct {
 	PullRequestName string `json:"pull_request_name"`
 	AuthorID        string `json:"author_id"`
 	Status          string `json:"status"`
+	IsStale         bool   `json:"is_stale"`
+}
+
+// PRRecord is a pull request as exported: one flat row, with the author's
+// team and without reviewers (those are exported as ReviewerRecord).
+type PRRecord struct {
+	PullRequestID   string     `json:"pull_request_id"`
+	PullRequestName string     `json:"pull_request_name"`
+	AuthorID        string     `json:"author_id"`
+	TeamName        string     `json:"team_name"`
+	Status          string     `json:"status"`
+	CreatedAt       time.Time  `json:"created_at"`
+	MergedAt        *time.Time `json:"merged_at,omitempty"`
+	StaleAt         *time.Time `json:"stale_at,omitempty"`
+	ClosedAt        *time.Time `json:"closed_at,omitempty"`
+}
+
+// ReviewerRecord is one current reviewer assignment as exported.
+type ReviewerRecord struct {
+	PullRequestID  string     `json:"pull_request_id"`
+	UserID         string     `json:"user_id"`
+	TeamName       string     `json:"team_name"`
+	AssignedAt     time.Time  `json:"assigned_at"`
+	SLAEscalatedAt *time.Time `json:"sla_escalated_at,omitempty"`
 }
 
 type ErrorResponse struct {
 	Error struct {
-		Code    string `json:"code"`
-		Message string `json:"message"`
+		Code      string       `json:"code"`
+		Message   string       `json:"message"`
+		RequestID string       `json:"request_id,omitempty"`
+		Details   []FieldError `json:"details,omitempty"`
 	} `json:"error"`
 }
+
+// BatchPRResult is the outcome of one item of /pullRequest/batchCreate or
+// /pullRequest/import: the created PR or the error that item ran into.
+type BatchPRResult struct {
+	// Index is the item's position in the input, from 0.
+	Index int `json:"index"`
+	// Line is the 1-based input line, set by the NDJSON import.
+	Line          int          `json:"line,omitempty"`
+	PullRequestID string       `json:"pull_request_id,omitempty"`
+	PR            *PullRequest `json:"pr,omitempty"`
+	Error         *ItemError   `json:"error,omitempty"`
+}
+
+// ItemError carries the same code, message and details as ErrorResponse
+// for a single item of a batch.
+type ItemError struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"`
+}
+
+type BatchPRResponse struct {
+	Created []BatchPRResult `json:"created"`
+	Failed  []BatchPRResult `json:"failed"`
+}
+
+// FieldError points at one invalid parameter or body field.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+type TeamSLA struct {
+	TeamName string `json:"team_name"`
+	SLAHours int    `json:"sla_hours"`
+	Policy   string `json:"policy"`
+}
+
+type SLABreach struct {
+	PullRequestID   string     `json:"pull_request_id"`
+	PullRequestName string     `json:"pull_request_name"`
+	AuthorID        string     `json:"author_id"`
+	TeamName        string     `json:"team_name"`
+	ReviewerID      string     `json:"reviewer_id"`
+	AssignedAt      time.Time  `json:"assigned_at"`
+	SLAHours        int        `json:"sla_hours"`
+	ElapsedHours    float64    `json:"elapsed_working_hours"`
+	Policy          string     `json:"policy"`
+	EscalatedAt     *time.Time `json:"escalated_at,omitempty"`
+	// ReviewerAdded is set once an escalation has added a reviewer to the
+	// pull request.
+	ReviewerAdded bool `json:"-"`
+}
+
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+}
+
+type AssignmentEvent struct {
+	ID             int64     `json:"id"`
+	PullRequestID  string    `json:"pull_request_id"`
+	Event          string    `json:"event"`
+	UserID         string    `json:"user_id"`
+	PreviousUserID string    `json:"previous_user_id,omitempty"`
+	Reason         string    `json:"reason"`
+	Actor          string    `json:"actor"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Snapshot is the archive of GET /admin/snapshot and POST
+// /admin/snapshot/restore. Version changes when the format does.
+type Snapshot struct {
+	Version       int                `json:"version"`
+	SchemaVersion int                `json:"schema_version"`
+	CreatedAt     time.Time          `json:"created_at"`
+	Teams         []SnapshotTeam     `json:"teams"`
+	Users         []SnapshotUser     `json:"users"`
+	PullRequests  []SnapshotPR       `json:"pull_requests"`
+	Reviewers     []SnapshotReviewer `json:"reviewers"`
+	History       []SnapshotEvent    `json:"history"`
+}
+
+type SnapshotTeam struct {
+	TeamName  string `json:"team_name"`
+	SLAHours  *int   `json:"sla_hours,omitempty"`
+	SLAPolicy string `json:"sla_policy"`
+}
+
+type SnapshotUser struct {
+	UserID      string   `json:"user_id"`
+	Username    string   `json:"username"`
+	TeamName    string   `json:"team_name"`
+	IsActive    bool     `json:"is_active"`
+	Email       string   `json:"email,omitempty"`
+	DailyDigest bool     `json:"daily_digest"`
+	Roles       []string `json:"roles,omitempty"`
+}
+
+type SnapshotPR struct {
+	PullRequestID   string     `json:"pull_request_id"`
+	PullRequestName string     `json:"pull_request_name"`
+	AuthorID        string     `json:"author_id"`
+	Status          string     `json:"status"`
+	CreatedAt       time.Time  `json:"created_at"`
+	MergedAt        *time.Time `json:"merged_at,omitempty"`
+	LastActivityAt  time.Time  `json:"last_activity_at"`
+	StaleAt         *time.Time `json:"stale_at,omitempty"`
+	ClosedAt        *time.Time `json:"closed_at,omitempty"`
+}
+
+type SnapshotReviewer struct {
+	PullRequestID  string     `json:"pull_request_id"`
+	UserID         string     `json:"user_id"`
+	AssignedAt     time.Time  `json:"assigned_at"`
+	SLAEscalatedAt *time.Time `json:"sla_escalated_at,omitempty"`
+}
+
+type SnapshotEvent struct {
+	PullRequestID  string    `json:"pull_request_id"`
+	Event          string    `json:"event"`
+	UserID         string    `json:"user_id"`
+	PreviousUserID string    `json:"previous_user_id,omitempty"`
+	Reason         string    `json:"reason"`
+	Actor          string    `json:"actor"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// RestoreCounts tells how many rows of one kind a restore wrote.
+type RestoreCounts struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+}
+
+type RestoreResult struct {
+	Mode         string        `json:"mode"`
+	Teams        RestoreCounts `json:"teams"`
+	Users        RestoreCounts `json:"users"`
+	PullRequests RestoreCounts `json:"pull_requests"`
+	Reviewers    RestoreCounts `json:"reviewers"`
+	History      RestoreCounts `json:"history"`
+}
+
+// Stats is the response of /stats. The maps keep the original raw counts;
+// the rest is computed over the requested team and period.
+type Stats struct {
+	// ReviewerAssignments is the number of current assignments per reviewer.
+	ReviewerAssignments map[string]int `json:"reviewer_assignments"`
+	// PRAssignments is the number of current reviewers per pull request.
+	PRAssignments     map[string]int `json:"pr_assignments"`
+	Users             []UserStats    `json:"users"`
+	TimeToMerge       DurationStats  `json:"time_to_merge"`
+	TimeToFirstReview DurationStats  `json:"time_to_first_review"`
+	Teams             []TeamStats    `json:"teams"`
+}
+
+// UserStats are the review assignments of one user: OpenAssignments on
+// pull requests that are still open, TotalAssignments made in the period.
+type UserStats struct {
+	UserID           string `json:"user_id"`
+	Username         string `json:"username"`
+	TeamName         string `json:"team_name"`
+	IsActive         bool   `json:"is_active"`
+	OpenAssignments  int    `json:"open_assignments"`
+	TotalAssignments int    `json:"total_assignments"`
+}
+
+// DurationStats summarizes Count durations. The percentiles are unset when
+// there are none.
+type DurationStats struct {
+	Count         int      `json:"count"`
+	MedianSeconds *float64 `json:"median_seconds,omitempty"`
+	P90Seconds    *float64 `json:"p90_seconds,omitempty"`
+}
+
+type TeamStats struct {
+	TeamName         string `json:"team_name"`
+	ActiveMembers    int    `json:"active_members"`
+	OpenAssignments  int    `json:"open_assignments"`
+	TotalAssignments int    `json:"total_assignments"`
+	// PullRequests were created by the team's members in the period, Merged
+	// of them have been merged since.
+	PullRequests      int           `json:"pull_requests"`
+	Merged            int           `json:"merged"`
+	TimeToMerge       DurationStats `json:"time_to_merge"`
+	TimeToFirstReview DurationStats `json:"time_to_first_review"`
+}
+
+// MemberLoad is the review load of one active team member: Assignments
+// made in the report's period and OpenAssignments held now.
+type MemberLoad struct {
+	UserID          string `json:"user_id"`
+	Username        string `json:"username"`
+	Assignments     int    `json:"assignments"`
+	OpenAssignments int    `json:"open_assignments"`
+}
+
+// FairnessReport tells how evenly a team's assignments in a period were
+// spread over its active members.
+type FairnessReport struct {
+	TeamName         string     `json:"team_name"`
+	From             *time.Time `json:"from,omitempty"`
+	To               *time.Time `json:"to,omitempty"`
+	ActiveMembers    int        `json:"active_members"`
+	TotalAssignments int        `json:"total_assignments"`
+	Mean             float64    `json:"mean"`
+	StdDev           float64    `json:"stddev"`
+	Gini             float64    `json:"gini"`
+	// MaxMinRatio is unset when the least loaded member has no assignments.
+	MaxMinRatio *float64     `json:"max_min_ratio,omitempty"`
+	Members     []MemberLoad `json:"members"`
+	// Overloaded and Underloaded members are more than one standard
+	// deviation above or below the mean.
+	Overloaded  []MemberLoad `json:"overloaded"`
+	Underloaded []MemberLoad `json:"underloaded"`
+}
+
+type RebalanceMove struct {
+	PullRequestID string `json:"pull_request_id"`
+	FromUserID    string `json:"from_user_id"`
+	ToUserID      string `json:"to_user_id"`
+}
+
+// RebalanceResult lists the moves of a rebalance and the members' open
+// assignments before and after it.
+type RebalanceResult struct {
+	TeamName string          `json:"team_name"`
+	Moves    []RebalanceMove `json:"moves"`
+	Before   map[string]int  `json:"open_before"`
+	After    map[string]int  `json:"open_after"`
+}
+
+// DeactivationResult tells what /team/deactivateUsers changed or, with
+// DryRun, would have changed. Every requested ID is in exactly one of
+// Deactivated, NotFound, OtherTeam and AlreadyInactive.
+type DeactivationResult struct {
+	DryRun          bool             `json:"dry_run"`
+	Deactivated     []string         `json:"deactivated"`
+	NotFound        []string         `json:"not_found"`
+	OtherTeam       []string         `json:"other_team"`
+	AlreadyInactive []string         `json:"already_inactive"`
+	PullRequests    []ReviewerChange `json:"pull_requests"`
+}
+
+// ReviewerChange is how the reviewers of one open pull request changed.
+// AddedReviewer is empty when nobody was added; LeftWithoutReviewers means
+// no active replacement was found, and Warning then says so.
+type ReviewerChange struct {
+	PullRequestID        string   `json:"pull_request_id"`
+	RemovedReviewers     []string `json:"removed_reviewers"`
+	AddedReviewer        string   `json:"added_reviewer,omitempty"`
+	LeftWithoutReviewers bool     `json:"left_without_reviewers"`
+	Warning              string   `json:"warning,omitempty"`
+}
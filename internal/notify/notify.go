@@ -0,0 +1,143 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+)
+
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+type Sender interface {
+	Send(ctx context.Context, m Message) error
+}
+
+type UserSource interface {
+	GetUser(ctx context.Context, userID string) (models.User, error)
+}
+
+// Notifier turns domain events into emails. A nil *Notifier is valid and
+// drops every event, so callers don't have to check whether SMTP is set up.
+type Notifier struct {
+	sender  Sender
+	users   UserSource
+	timeout time.Duration
+}
+
+func New(sender Sender, users UserSource) *Notifier {
+	return &Notifier{sender: sender, users: users, timeout: 30 * time.Second}
+}
+
+// ReviewersAssigned emails every reviewer in userIDs about pr. It runs in the
+// background: delivery failures are logged and never reach the caller.
+func (n *Notifier) ReviewersAssigned(pr models.PullRequest, userIDs []string) {
+	if n == nil || len(userIDs) == 0 {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), n.timeout)
+		defer cancel()
+
+		for _, uid := range userIDs {
+			u, err := n.users.GetUser(ctx, uid)
+			if err != nil {
+				slog.Error("notify: lookup reviewer", "user_id", uid, "error", err)
+				continue
+			}
+			if u.Email == "" {
+				continue
+			}
+			if err := n.sender.Send(ctx, AssignmentMessage(pr, u)); err != nil {
+				slog.Error("notify: send assignment email", "user_id", uid, "error", err)
+			}
+		}
+	}()
+}
+
+func (n *Notifier) Send(ctx context.Context, m Message) error {
+	if n == nil {
+		return nil
+	}
+	return n.sender.Send(ctx, m)
+}
+
+func AssignmentMessage(pr models.PullRequest, u models.User) Message {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hi %s,\n\n", u.Username)
+	fmt.Fprintf(&b, "You have been assigned to review pull request %s.\n\n", pr.PullRequestID)
+	fmt.Fprintf(&b, "  Name:   %s\n", pr.PullRequestName)
+	fmt.Fprintf(&b, "  Author: %s\n", pr.AuthorID)
+
+	return Message{
+		To:      []string{u.Email},
+		Subject: fmt.Sprintf("Review requested: %s", pr.PullRequestName),
+		Body:    b.String(),
+	}
+}
+
+func DigestMessage(u models.User, prs []models.PullRequestShort) Message {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hi %s,\n\n", u.Username)
+	fmt.Fprintf(&b, "You have %d open pull request(s) waiting for your review:\n\n", len(prs))
+	for _, p := range prs {
+		fmt.Fprintf(&b, "  - %s: %s (author %s)\n", p.PullRequestID, p.PullRequestName, p.AuthorID)
+	}
+
+	return Message{
+		To:      []string{u.Email},
+		Subject: fmt.Sprintf("Daily review digest: %d open", len(prs)),
+		Body:    b.String(),
+	}
+}
+
+func SLABreachMessage(b models.SLABreach, to []string) Message {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Review of pull request %s (%s) by %s has exceeded the %dh SLA of team %s.\n\n",
+		b.PullRequestID, b.PullRequestName, b.ReviewerID, b.SLAHours, b.TeamName)
+	fmt.Fprintf(&sb, "  Assigned at:          %s\n", b.AssignedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&sb, "  Working hours waited: %.1f\n", b.ElapsedHours)
+
+	return Message{
+		To:      to,
+		Subject: fmt.Sprintf("Review SLA breached: %s", b.PullRequestName),
+		Body:    sb.String(),
+	}
+}
+
+func StaleMessage(pr models.PullRequestShort, author models.User, closeAfter time.Duration) Message {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hi %s,\n\n", author.Username)
+	fmt.Fprintf(&b, "Your pull request %s (%s) has had no activity for a while and is now marked stale.\n",
+		pr.PullRequestID, pr.PullRequestName)
+	if closeAfter > 0 {
+		fmt.Fprintf(&b, "It will be closed automatically in %.0f day(s) unless there is new activity.\n", closeAfter.Hours()/24)
+	}
+
+	return Message{
+		To:      []string{author.Email},
+		Subject: fmt.Sprintf("Stale pull request: %s", pr.PullRequestName),
+		Body:    b.String(),
+	}
+}
+
+func StaleClosedMessage(pr models.PullRequestShort, author models.User) Message {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hi %s,\n\n", author.Username)
+	fmt.Fprintf(&b, "Your pull request %s (%s) stayed stale past the grace period and has been closed.\n",
+		pr.PullRequestID, pr.PullRequestName)
+
+	return Message{
+		To:      []string{author.Email},
+		Subject: fmt.Sprintf("Closed stale pull request: %s", pr.PullRequestName),
+		Body:    b.String(),
+	}
+}
@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTP accepts a single plain-text session and records what it got.
+type fakeSMTP struct {
+	ln   net.Listener
+	from string
+	rcpt []string
+	data string
+	done chan struct{}
+}
+
+func startFakeSMTP(t *testing.T) *fakeSMTP {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	f := &fakeSMTP{ln: ln, done: make(chan struct{})}
+	t.Cleanup(func() { _ = ln.Close() })
+	go f.serve()
+	return f
+}
+
+func (f *fakeSMTP) serve() {
+	defer close(f.done)
+	conn, err := f.ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(s string) { _, _ = conn.Write([]byte(s + "\r\n")) }
+	reply("220 fake ESMTP")
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 fake")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			f.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			reply("250 ok")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			f.rcpt = append(f.rcpt, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			reply("250 ok")
+		case cmd == "DATA":
+			reply("354 go ahead")
+			var b strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				b.WriteString(l)
+			}
+			f.data = b.String()
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 unsupported")
+		}
+	}
+}
+
+func TestSMTPSendPlain(t *testing.T) {
+	f := startFakeSMTP(t)
+	addr := f.ln.Addr().(*net.TCPAddr)
+
+	s := NewSMTP(SMTPConfig{
+		Host: "127.0.0.1",
+		Port: addr.Port,
+		From: "bot@example.com",
+	})
+
+	err := s.Send(context.Background(), Message{
+		To:      []string{"alice@example.com"},
+		Subject: "Review requested: Add search",
+		Body:    "line one\nline two\n",
+	})
+	require.NoError(t, err)
+	<-f.done
+
+	require.Equal(t, "bot@example.com", f.from)
+	require.Equal(t, []string{"alice@example.com"}, f.rcpt)
+	require.Contains(t, f.data, "To: alice@example.com\r\n")
+	require.Contains(t, f.data, "Subject: Review requested: Add search\r\n")
+	require.Contains(t, f.data, "\r\n\r\nline one\r\nline two\r\n")
+}
+
+func TestSMTPSendNoRecipients(t *testing.T) {
+	s := NewSMTP(SMTPConfig{Host: "127.0.0.1", Port: 1})
+	require.NoError(t, s.Send(context.Background(), Message{}))
+}
+
+func TestSMTPDialError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	require.NoError(t, ln.Close())
+
+	s := NewSMTP(SMTPConfig{Host: "127.0.0.1", Port: port, From: "bot@example.com"})
+	err = s.Send(context.Background(), Message{To: []string{"a@example.com"}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "smtp dial")
+}
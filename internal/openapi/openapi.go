@@ -0,0 +1,270 @@
+// Package openapi validates requests, and optionally responses, against the
+// embedded openapi.yml so the handlers and the published contract cannot
+// drift apart unnoticed.
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+
+	api "Backend-trainee-assignment-autumn-2025"
+	"Backend-trainee-assignment-autumn-2025/internal/logging"
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+)
+
+// Validator checks traffic of the routes registered through Middleware.
+type Validator struct {
+	doc       *openapi3.T
+	responses bool
+}
+
+type Option func(*Validator)
+
+// WithResponseValidation also checks every response against the spec and
+// replaces a non-conforming one with a 500 naming the mismatch. It buffers
+// whole responses and is meant for test and staging environments.
+func WithResponseValidation() Option {
+	return func(v *Validator) {
+		v.responses = true
+	}
+}
+
+// Schema errors otherwise embed the whole schema and value in Error(),
+// which would end up in logs and responses.
+func init() {
+	openapi3.SchemaErrorDetailsDisabled = true
+}
+
+// New loads and validates the embedded specification.
+func New(opts ...Option) (*Validator, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(api.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("load openapi spec: %w", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid openapi spec: %w", err)
+	}
+	v := &Validator{doc: doc}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v, nil
+}
+
+var filterOptions = &openapi3filter.Options{
+	MultiError:          true,
+	SkipSettingDefaults: true,
+	// Credentials are checked by the handlers' own middleware.
+	AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+}
+
+// streamOptions skip the bodies of streamed media types: validating them
+// would mean buffering the stream, and their handlers check each record.
+var streamOptions = func() *openapi3filter.Options {
+	o := *filterOptions
+	o.ExcludeRequestBody = true
+	o.ExcludeResponseBody = true
+	return &o
+}()
+
+var streamed = map[string]bool{
+	"application/x-ndjson": true,
+	"text/csv":             true,
+}
+
+func isStreamed(h http.Header) bool {
+	mt, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	return streamed[mt]
+}
+
+// Middleware validates requests to the route registered under pattern. It
+// panics if pattern is not a path of the spec: every served route must be
+// documented.
+func (v *Validator) Middleware(pattern string, next http.Handler) http.Handler {
+	item := v.doc.Paths.Value(pattern)
+	if item == nil {
+		panic("openapi: route " + pattern + " is not described in openapi.yml")
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		op := item.GetOperation(r.Method)
+		if op == nil {
+			// The handler answers 405 itself.
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Clients such as curl omit the header; JSON is the only body type.
+		if r.ContentLength != 0 && r.Header.Get("Content-Type") == "" {
+			r.Header.Set("Content-Type", "application/json")
+		}
+
+		in := &openapi3filter.RequestValidationInput{
+			Request: r,
+			Route: &routers.Route{
+				Spec:      v.doc,
+				Path:      pattern,
+				PathItem:  item,
+				Method:    r.Method,
+				Operation: op,
+			},
+			Options: filterOptions,
+		}
+		if isStreamed(r.Header) {
+			in.Options = streamOptions
+		}
+		if err := openapi3filter.ValidateRequest(r.Context(), in); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeError(w, http.StatusRequestEntityTooLarge, "INVALID",
+					fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit), nil)
+				return
+			}
+			writeInvalid(w, fieldErrors(err))
+			return
+		}
+
+		if !v.responses {
+			next.ServeHTTP(w, r)
+			return
+		}
+		rec := &bufferedWriter{ResponseWriter: w, code: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if err := v.validateResponse(r.Context(), in, rec); err != nil {
+			logging.FromContext(r.Context()).Error("response does not match openapi.yml",
+				"route", pattern, "status", rec.code, "err", err)
+			writeError(w, http.StatusInternalServerError, "ERROR", "response does not match openapi.yml: "+err.Error(), nil)
+			return
+		}
+		w.WriteHeader(rec.code)
+		_, _ = w.Write(rec.body.Bytes())
+	})
+}
+
+// validateResponse checks rec against the operation's declared responses.
+// Error statuses an operation does not list must still be ErrorResponse
+// bodies; undeclared success statuses are drift.
+func (v *Validator) validateResponse(ctx context.Context, in *openapi3filter.RequestValidationInput, rec *bufferedWriter) error {
+	responses := in.Route.Operation.Responses
+	if responses.Status(rec.code) == nil && responses.Default() == nil {
+		if rec.code < 400 {
+			return fmt.Errorf("status %d is not declared", rec.code)
+		}
+		if rec.code == http.StatusMethodNotAllowed {
+			return nil
+		}
+		var body interface{}
+		if err := json.Unmarshal(rec.body.Bytes(), &body); err != nil {
+			return fmt.Errorf("error body is not JSON: %w", err)
+		}
+		return v.doc.Components.Schemas["ErrorResponse"].Value.VisitJSON(body)
+	}
+
+	opts := filterOptions
+	if isStreamed(rec.Header()) {
+		opts = streamOptions
+	}
+	return openapi3filter.ValidateResponse(ctx, &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: in,
+		Status:                 rec.code,
+		Header:                 rec.Header(),
+		Body:                   io.NopCloser(bytes.NewReader(rec.body.Bytes())),
+		Options:                opts,
+	})
+}
+
+// fieldErrors flattens validation errors into one entry per offending
+// parameter or body field.
+func fieldErrors(err error) []models.FieldError {
+	var out []models.FieldError
+	var walk func(field string, err error)
+	walk = func(field string, err error) {
+		switch e := err.(type) {
+		case openapi3.MultiError:
+			for _, inner := range e {
+				walk(field, inner)
+			}
+		case *openapi3filter.RequestError:
+			if e.Parameter != nil {
+				field = e.Parameter.Name
+			}
+			if e.Err == nil {
+				out = append(out, models.FieldError{Field: field, Reason: e.Reason})
+				return
+			}
+			walk(field, e.Err)
+		case *openapi3.SchemaError:
+			path := e.JSONPointer()
+			if field != "" {
+				path = append([]string{field}, path...)
+			}
+			out = append(out, models.FieldError{Field: strings.Join(path, "."), Reason: e.Reason})
+		default:
+			out = append(out, models.FieldError{Field: field, Reason: err.Error()})
+		}
+	}
+	walk("", err)
+	return out
+}
+
+func writeInvalid(w http.ResponseWriter, details []models.FieldError) {
+	msg := "request does not match the API schema"
+	if len(details) > 0 {
+		d := details[0]
+		msg = d.Reason
+		if d.Field != "" {
+			msg = d.Field + ": " + d.Reason
+		}
+	}
+	writeError(w, http.StatusBadRequest, "INVALID", msg, details)
+}
+
+func writeError(w http.ResponseWriter, status int, code, msg string, details []models.FieldError) {
+	var resp models.ErrorResponse
+	resp.Error.Code = code
+	resp.Error.Message = msg
+	resp.Error.Details = details
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error("write response", "err", err)
+	}
+}
+
+// bufferedWriter holds the status and body back until the response has been
+// validated. Headers go straight to the underlying writer's map.
+type bufferedWriter struct {
+	http.ResponseWriter
+	code        int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (b *bufferedWriter) WriteHeader(code int) {
+	if b.wroteHeader {
+		return
+	}
+	b.code, b.wroteHeader = code, true
+}
+
+func (b *bufferedWriter) Write(p []byte) (int, error) {
+	b.wroteHeader = true
+	return b.body.Write(p)
+}
+
+// Paths lists the paths the spec documents.
+func (v *Validator) Paths() []string {
+	return v.doc.Paths.InMatchingOrder()
+}
@@ -0,0 +1,199 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"Backend-trainee-assignment-autumn-2025/internal/auth"
+	"Backend-trainee-assignment-autumn-2025/internal/handlers"
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+	"Backend-trainee-assignment-autumn-2025/internal/openapi"
+)
+
+func serve(t *testing.T, h http.Handler, method, target, body string) (*httptest.ResponseRecorder, models.ErrorResponse) {
+	t.Helper()
+	var r *http.Request
+	if body == "" {
+		r = httptest.NewRequest(method, target, nil)
+	} else {
+		r = httptest.NewRequest(method, target, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	var er models.ErrorResponse
+	if w.Code >= 400 {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &er), w.Body.String())
+	}
+	return w, er
+}
+
+func ok(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"status":"ok"}`))
+}
+
+func TestRejectsMissingRequiredField(t *testing.T) {
+	v, err := openapi.New()
+	require.NoError(t, err)
+	h := v.Middleware("/pullRequest/merge", http.HandlerFunc(ok))
+
+	w, er := serve(t, h, http.MethodPost, "/pullRequest/merge", `{}`)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, "INVALID", er.Error.Code)
+	require.Len(t, er.Error.Details, 1)
+	require.Equal(t, "pull_request_id", er.Error.Details[0].Field)
+}
+
+func TestReportsEveryBodyField(t *testing.T) {
+	v, err := openapi.New()
+	require.NoError(t, err)
+	h := v.Middleware("/team/add", http.HandlerFunc(ok))
+
+	_, er := serve(t, h, http.MethodPost, "/team/add",
+		`{"team_name": 5, "members": [{"user_id": "u1", "username": "a"}]}`)
+
+	var fields []string
+	for _, d := range er.Error.Details {
+		fields = append(fields, d.Field)
+	}
+	require.ElementsMatch(t, []string{"team_name", "members.0.is_active"}, fields)
+}
+
+func TestRejectsBadQueryParameter(t *testing.T) {
+	v, err := openapi.New()
+	require.NoError(t, err)
+	h := v.Middleware("/audit", http.HandlerFunc(ok))
+
+	w, er := serve(t, h, http.MethodGet, "/audit?limit=1000", "")
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, "limit", er.Error.Details[0].Field)
+}
+
+func TestPassesValidRequestAndOtherMethods(t *testing.T) {
+	v, err := openapi.New()
+	require.NoError(t, err)
+	h := v.Middleware("/pullRequest/merge", http.HandlerFunc(ok))
+
+	w, _ := serve(t, h, http.MethodPost, "/pullRequest/merge", `{"pull_request_id":"pr-1"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// Not an operation of the path: left to the handler's own 405.
+	w, _ = serve(t, h, http.MethodDelete, "/pullRequest/merge", "")
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestResponseValidation(t *testing.T) {
+	v, err := openapi.New(openapi.WithResponseValidation())
+	require.NoError(t, err)
+	respond := func(code int, body string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(code)
+			_, _ = w.Write([]byte(body))
+		})
+	}
+	req := `{"pull_request_id":"pr-1"}`
+
+	tests := []struct {
+		name string
+		code int
+		body string
+		want int
+	}{
+		{"conforming", 200, `{"pr":{"pull_request_id":"pr-1","pull_request_name":"n","author_id":"u1","status":"MERGED","assigned_reviewers":[]}}`, 200},
+		{"missing required field", 200, `{"pr":{"pull_request_id":"pr-1"}}`, 500},
+		{"undeclared success status", 201, `{}`, 500},
+		{"undeclared error status with ErrorResponse", 400, `{"error":{"code":"INVALID","message":"bad request"}}`, 400},
+		{"undeclared error status with other body", 400, `{"message":"bad request"}`, 500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := v.Middleware("/pullRequest/merge", respond(tt.code, tt.body))
+			w, _ := serve(t, h, http.MethodPost, "/pullRequest/merge", req)
+			require.Equal(t, tt.want, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestUndocumentedRoutePanics(t *testing.T) {
+	v, err := openapi.New()
+	require.NoError(t, err)
+	require.Panics(t, func() { v.Middleware("/debug/reset", http.HandlerFunc(ok)) })
+}
+
+// TestSpecMatchesRoutes fails when a handler route is missing from
+// openapi.yml (Middleware panics) or a documented path is not served.
+func TestSpecMatchesRoutes(t *testing.T) {
+	v, err := openapi.New()
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	handlers.RegisterHandlers(mux, nil, handlers.WithValidator(v))
+
+	for _, path := range v.Paths() {
+		_, pattern := mux.Handler(httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, path, pattern, "documented path is not routed")
+	}
+}
+
+// Anonymous callers are turned away before their body is checked, so they
+// get 401 rather than schema details.
+func TestAuthenticatesBeforeValidating(t *testing.T) {
+	v, err := openapi.New()
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	handlers.RegisterHandlers(mux, nil, handlers.WithValidator(v),
+		handlers.WithAuthenticator(auth.NewAuthenticator(nil, nil)))
+
+	w, er := serve(t, mux, http.MethodPost, "/pullRequest/merge", `{"unexpected":1}`)
+
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Equal(t, "UNAUTHORIZED", er.Error.Code)
+	require.Empty(t, er.Error.Details)
+}
+
+func TestBodyTooLarge(t *testing.T) {
+	v, err := openapi.New()
+	require.NoError(t, err)
+	h := http.MaxBytesHandler(v.Middleware("/pullRequest/merge", http.HandlerFunc(ok)), 32)
+
+	w, er := serve(t, h, http.MethodPost, "/pullRequest/merge", `{"pull_request_id":"`+strings.Repeat("x", 64)+`"}`)
+
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	require.Equal(t, "INVALID", er.Error.Code)
+}
+
+// NDJSON bodies are streamed to the handler unread, and the same route's
+// JSON requests are still rejected.
+func TestStreamedBodiesPassThrough(t *testing.T) {
+	v, err := openapi.New(openapi.WithResponseValidation())
+	require.NoError(t, err)
+	var got string
+	h := v.Middleware("/pullRequest/import", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got = string(b)
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte(`{"index":0,"line":1}` + "\n"))
+	}))
+
+	body := `{"pull_request_id":"pr-1"}` + "\n" + `not json` + "\n"
+	r := httptest.NewRequest(http.MethodPost, "/pullRequest/import", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-ndjson")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, body, got)
+
+	w, _ = serve(t, h, http.MethodPost, "/pullRequest/import", `[]`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
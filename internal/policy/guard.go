@@ -0,0 +1,282 @@
+package policy
+
+import (
+	"context"
+	"io"
+
+	"Backend-trainee-assignment-autumn-2025/internal/auth"
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+	"Backend-trainee-assignment-autumn-2025/internal/storage"
+)
+
+// Store is the part of *storage.Store the Guard checks calls to.
+type Store interface {
+	GetUser(ctx context.Context, userID string) (models.User, error)
+	GetUserTeams(ctx context.Context, userIDs []string) (map[string]string, error)
+	UpsertTeam(ctx context.Context, t models.Team) error
+	BulkDeactivateUsers(ctx context.Context, teamName string, userIDs []string, dryRun bool) (storage.Deactivation, error)
+	SetTeamSLA(ctx context.Context, sla models.TeamSLA) error
+	RebalanceTeam(ctx context.Context, teamName string, maxMoves int) (storage.Rebalance, error)
+	SetUserActive(ctx context.Context, userID string, isActive bool) (models.User, error)
+	SetUserNotifications(ctx context.Context, userID, email string, dailyDigest bool) (models.User, error)
+	SetUserRoles(ctx context.Context, userID string, roles []string) error
+	CreatePR(ctx context.Context, pr models.PullRequest) (models.PullRequest, error)
+	CreatePRs(ctx context.Context, prs []models.PullRequest) ([]storage.PRCreateResult, error)
+	GetPR(ctx context.Context, prID string) (models.PullRequest, error)
+	MergePR(ctx context.Context, prID string) (models.PullRequest, error)
+	ReassignReviewer(ctx context.Context, prID, oldUserID, reason string, dryRun bool) (models.PullRequest, string, error)
+	WriteSnapshot(ctx context.Context, w io.Writer) error
+	RestoreSnapshot(ctx context.Context, snap models.Snapshot, mode string) (models.RestoreResult, error)
+	ListAudit(ctx context.Context, f storage.AuditFilter) ([]models.AuditEntry, error)
+	ExportUsers(ctx context.Context, f storage.ExportFilter, fn func(models.User) error) error
+}
+
+// Guard wraps the Store operations that are subject to role checks. It
+// resolves the caller from the request context, authorizes the call and
+// only then hands it to the store.
+type Guard struct {
+	store Store
+}
+
+func NewGuard(st Store) *Guard {
+	return &Guard{store: st}
+}
+
+func (g *Guard) subject(ctx context.Context) (Subject, error) {
+	p, ok := auth.FromContext(ctx)
+	if !ok || p.UserID == "" {
+		return Subject{Unrestricted: true}, nil
+	}
+
+	sub := Subject{UserID: p.UserID, Roles: p.Roles}
+	u, err := g.store.GetUser(ctx, p.UserID)
+	switch err {
+	case nil:
+		sub.TeamName = u.TeamName
+	case storage.ErrUserNotFound:
+	default:
+		return sub, err
+	}
+	return sub, nil
+}
+
+func (g *Guard) check(ctx context.Context, act Action, res Resource) error {
+	sub, err := g.subject(ctx)
+	if err != nil {
+		return err
+	}
+	return Authorize(sub, act, res)
+}
+
+func (g *Guard) UpsertTeam(ctx context.Context, t models.Team) error {
+	ids := make([]string, 0, len(t.Members))
+	for _, m := range t.Members {
+		ids = append(ids, m.UserID)
+	}
+	current, err := g.store.GetUserTeams(ctx, ids)
+	if err != nil {
+		return err
+	}
+	memberTeams := make([]string, 0, len(current))
+	for _, team := range current {
+		memberTeams = append(memberTeams, team)
+	}
+
+	if err := g.check(ctx, ActionTeamAdd, Resource{TeamName: t.TeamName, MemberTeams: memberTeams}); err != nil {
+		return err
+	}
+	return g.store.UpsertTeam(ctx, t)
+}
+
+func (g *Guard) BulkDeactivateUsers(ctx context.Context, teamName string, userIDs []string, dryRun bool) (storage.Deactivation, error) {
+	if err := g.check(ctx, ActionTeamDeactivate, Resource{TeamName: teamName}); err != nil {
+		return storage.Deactivation{}, err
+	}
+	return g.store.BulkDeactivateUsers(ctx, teamName, userIDs, dryRun)
+}
+
+func (g *Guard) SetTeamSLA(ctx context.Context, sla models.TeamSLA) error {
+	if err := g.check(ctx, ActionTeamSetSLA, Resource{TeamName: sla.TeamName}); err != nil {
+		return err
+	}
+	return g.store.SetTeamSLA(ctx, sla)
+}
+
+func (g *Guard) RebalanceTeam(ctx context.Context, teamName string, maxMoves int) (storage.Rebalance, error) {
+	if err := g.check(ctx, ActionTeamRebalance, Resource{TeamName: teamName}); err != nil {
+		return storage.Rebalance{}, err
+	}
+	return g.store.RebalanceTeam(ctx, teamName, maxMoves)
+}
+
+func (g *Guard) SetUserActive(ctx context.Context, userID string, isActive bool) (models.User, error) {
+	u, err := g.store.GetUser(ctx, userID)
+	if err != nil {
+		return u, err
+	}
+	if err := g.check(ctx, ActionUserSetActive, Resource{UserID: userID, TeamName: u.TeamName}); err != nil {
+		return models.User{}, err
+	}
+	return g.store.SetUserActive(ctx, userID, isActive)
+}
+
+func (g *Guard) SetUserNotifications(ctx context.Context, userID, email string, dailyDigest bool) (models.User, error) {
+	u, err := g.store.GetUser(ctx, userID)
+	if err != nil {
+		return u, err
+	}
+	if err := g.check(ctx, ActionUserSetNotify, Resource{UserID: userID, TeamName: u.TeamName}); err != nil {
+		return models.User{}, err
+	}
+	return g.store.SetUserNotifications(ctx, userID, email, dailyDigest)
+}
+
+func (g *Guard) SetUserRoles(ctx context.Context, userID string, roles []string) error {
+	if err := g.check(ctx, ActionUserSetRoles, Resource{UserID: userID}); err != nil {
+		return err
+	}
+	return g.store.SetUserRoles(ctx, userID, roles)
+}
+
+// checkAuthor authorizes act on a pull request by authorID. The author's
+// team decides which lead may act for them.
+func (g *Guard) checkAuthor(ctx context.Context, act Action, authorID string) error {
+	res := Resource{AuthorID: authorID}
+	u, err := g.store.GetUser(ctx, authorID)
+	switch err {
+	case nil:
+		res.TeamName = u.TeamName
+	case storage.ErrUserNotFound:
+	default:
+		return err
+	}
+	return g.check(ctx, act, res)
+}
+
+// CreatePR opens a pull request on behalf of its author: the caller
+// themselves, or a member of the team they lead.
+func (g *Guard) CreatePR(ctx context.Context, pr models.PullRequest) (models.PullRequest, error) {
+	if err := g.checkAuthor(ctx, ActionPullRequestCreate, pr.AuthorID); err != nil {
+		return models.PullRequest{}, err
+	}
+	return g.store.CreatePR(ctx, pr)
+}
+
+// CreatePRs opens pull requests in bulk under the rule of CreatePR. Items the
+// caller may not open fail on their own with ErrForbidden.
+func (g *Guard) CreatePRs(ctx context.Context, prs []models.PullRequest) ([]storage.PRCreateResult, error) {
+	sub, err := g.subject(ctx)
+	if err != nil {
+		return nil, err
+	}
+	authors := make([]string, len(prs))
+	for i, pr := range prs {
+		authors[i] = pr.AuthorID
+	}
+	teams, err := g.store.GetUserTeams(ctx, authors)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]storage.PRCreateResult, len(prs))
+	allowed := make([]models.PullRequest, 0, len(prs))
+	slots := make([]int, 0, len(prs))
+	for i, pr := range prs {
+		res := Resource{AuthorID: pr.AuthorID, TeamName: teams[pr.AuthorID]}
+		if err := Authorize(sub, ActionPullRequestCreate, res); err != nil {
+			results[i] = storage.PRCreateResult{PR: pr, Err: err}
+			continue
+		}
+		allowed = append(allowed, pr)
+		slots = append(slots, i)
+	}
+	created, err := g.store.CreatePRs(ctx, allowed)
+	if err != nil {
+		return nil, err
+	}
+	for i, res := range created {
+		results[slots[i]] = res
+	}
+	return results, nil
+}
+
+func (g *Guard) ReassignReviewer(ctx context.Context, prID, oldUserID, reason string, dryRun bool) (models.PullRequest, string, error) {
+	pr, err := g.store.GetPR(ctx, prID)
+	if err != nil {
+		return pr, "", err
+	}
+	if err := g.checkAuthor(ctx, ActionPullRequestReassign, pr.AuthorID); err != nil {
+		return models.PullRequest{}, "", err
+	}
+	return g.store.ReassignReviewer(ctx, prID, oldUserID, reason, dryRun)
+}
+
+func (g *Guard) MergePR(ctx context.Context, prID string) (models.PullRequest, error) {
+	pr, err := g.store.GetPR(ctx, prID)
+	if err != nil {
+		return pr, err
+	}
+	if err := g.check(ctx, ActionPullRequestMerge, Resource{AuthorID: pr.AuthorID}); err != nil {
+		return models.PullRequest{}, err
+	}
+	return g.store.MergePR(ctx, prID)
+}
+
+// WriteSnapshot and RestoreSnapshot span every team and carry roles, so
+// only admins may call them.
+func (g *Guard) WriteSnapshot(ctx context.Context, w io.Writer) error {
+	if err := g.check(ctx, ActionSnapshotExport, Resource{}); err != nil {
+		return err
+	}
+	return g.store.WriteSnapshot(ctx, w)
+}
+
+func (g *Guard) RestoreSnapshot(ctx context.Context, snap models.Snapshot, mode string) (models.RestoreResult, error) {
+	if err := g.check(ctx, ActionSnapshotRestore, Resource{}); err != nil {
+		return models.RestoreResult{}, err
+	}
+	return g.store.RestoreSnapshot(ctx, snap, mode)
+}
+
+// ListAudit reads the log of every team, including users' contact details,
+// so it is admin-only as well.
+func (g *Guard) ListAudit(ctx context.Context, f storage.AuditFilter) ([]models.AuditEntry, error) {
+	if err := g.check(ctx, ActionAuditRead, Resource{}); err != nil {
+		return nil, err
+	}
+	return g.store.ListAudit(ctx, f)
+}
+
+// ExportUsers streams users like the store does but blanks their email
+// addresses unless the caller may manage users: admin:teams for API keys,
+// the admin role for users.
+func (g *Guard) ExportUsers(ctx context.Context, f storage.ExportFilter, fn func(models.User) error) error {
+	contacts := true
+	if p, ok := auth.FromContext(ctx); ok && !p.HasScope(auth.ScopeAdminTeams) {
+		contacts = false
+	}
+	if contacts {
+		switch err := g.check(ctx, ActionUserContacts, Resource{}); err {
+		case nil:
+		case ErrForbidden:
+			contacts = false
+		default:
+			return err
+		}
+	}
+	return g.store.ExportUsers(ctx, f, func(u models.User) error {
+		if !contacts {
+			u.Email = ""
+		}
+		return fn(u)
+	})
+}
+
+func ValidRole(r string) bool {
+	for _, v := range Roles {
+		if v == r {
+			return true
+		}
+	}
+	return false
+}
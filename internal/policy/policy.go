@@ -0,0 +1,110 @@
+package policy
+
+import (
+	"errors"
+
+	"Backend-trainee-assignment-autumn-2025/internal/auth"
+)
+
+const (
+	RoleAdmin    = auth.RoleAdmin
+	RoleTeamLead = auth.RoleTeamLead
+)
+
+var Roles = []string{RoleAdmin, RoleTeamLead}
+
+var ErrForbidden = errors.New("forbidden")
+
+type Action string
+
+const (
+	ActionTeamAdd             Action = "team:add"
+	ActionTeamDeactivate      Action = "team:deactivateUsers"
+	ActionTeamSetSLA          Action = "team:setSLA"
+	ActionTeamRebalance       Action = "team:rebalance"
+	ActionUserSetActive       Action = "user:setIsActive"
+	ActionUserSetNotify       Action = "user:setNotifications"
+	ActionUserSetRoles        Action = "user:setRoles"
+	ActionPullRequestCreate   Action = "pr:create"
+	ActionPullRequestMerge    Action = "pr:merge"
+	ActionPullRequestReassign Action = "pr:reassign"
+	ActionSnapshotExport      Action = "snapshot:export"
+	ActionSnapshotRestore     Action = "snapshot:restore"
+	ActionAuditRead           Action = "audit:read"
+	ActionUserContacts        Action = "user:contacts"
+)
+
+// Subject is who performs an action. Unrestricted subjects are trusted
+// callers without a user identity: API keys (already limited by their
+// scopes) and every caller when authentication is off.
+type Subject struct {
+	Unrestricted bool
+	UserID       string
+	TeamName     string
+	Roles        []string
+}
+
+func (s Subject) has(role string) bool {
+	for _, r := range s.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Resource describes what an action touches. Only the fields relevant to the
+// action are set.
+type Resource struct {
+	TeamName string
+	UserID   string
+	AuthorID string
+	// MemberTeams are the current teams of users a team upsert would move.
+	MemberTeams []string
+}
+
+// Authorize decides whether sub may perform act on res.
+func Authorize(sub Subject, act Action, res Resource) error {
+	if sub.Unrestricted || sub.has(RoleAdmin) {
+		return nil
+	}
+
+	leadOf := func(team string) bool {
+		return sub.has(RoleTeamLead) && sub.TeamName != "" && sub.TeamName == team
+	}
+
+	switch act {
+	case ActionTeamAdd:
+		if !leadOf(res.TeamName) {
+			return ErrForbidden
+		}
+		// A lead must not pull users over from other teams.
+		for _, t := range res.MemberTeams {
+			if t != res.TeamName {
+				return ErrForbidden
+			}
+		}
+		return nil
+
+	case ActionTeamDeactivate, ActionTeamSetSLA, ActionTeamRebalance:
+		if leadOf(res.TeamName) {
+			return nil
+		}
+
+	case ActionUserSetActive, ActionUserSetNotify:
+		if sub.UserID == res.UserID || leadOf(res.TeamName) {
+			return nil
+		}
+
+	case ActionPullRequestCreate, ActionPullRequestReassign:
+		if (sub.UserID != "" && sub.UserID == res.AuthorID) || leadOf(res.TeamName) {
+			return nil
+		}
+
+	case ActionPullRequestMerge:
+		if sub.UserID != "" && sub.UserID == res.AuthorID {
+			return nil
+		}
+	}
+	return ErrForbidden
+}
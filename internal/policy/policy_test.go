@@ -0,0 +1,103 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorize(t *testing.T) {
+	admin := Subject{UserID: "a1", TeamName: "ops", Roles: []string{RoleAdmin}}
+	lead := Subject{UserID: "l1", TeamName: "backend", Roles: []string{RoleTeamLead}}
+	leadNoTeam := Subject{UserID: "l2", Roles: []string{RoleTeamLead}}
+	user := Subject{UserID: "u1", TeamName: "backend"}
+	service := Subject{Unrestricted: true}
+
+	tests := []struct {
+		name  string
+		sub   Subject
+		act   Action
+		res   Resource
+		allow bool
+	}{
+		{"admin adds any team", admin, ActionTeamAdd, Resource{TeamName: "frontend", MemberTeams: []string{"backend"}}, true},
+		{"service adds any team", service, ActionTeamAdd, Resource{TeamName: "frontend"}, true},
+		{"lead adds own team", lead, ActionTeamAdd, Resource{TeamName: "backend", MemberTeams: []string{"backend"}}, true},
+		{"lead adds own team with new users", lead, ActionTeamAdd, Resource{TeamName: "backend"}, true},
+		{"lead adds other team", lead, ActionTeamAdd, Resource{TeamName: "frontend"}, false},
+		{"lead pulls users from other team", lead, ActionTeamAdd, Resource{TeamName: "backend", MemberTeams: []string{"backend", "frontend"}}, false},
+		{"lead without team", leadNoTeam, ActionTeamAdd, Resource{TeamName: ""}, false},
+		{"user adds team", user, ActionTeamAdd, Resource{TeamName: "backend"}, false},
+
+		{"admin deactivates any team", admin, ActionTeamDeactivate, Resource{TeamName: "frontend"}, true},
+		{"lead deactivates own team", lead, ActionTeamDeactivate, Resource{TeamName: "backend"}, true},
+		{"lead deactivates other team", lead, ActionTeamDeactivate, Resource{TeamName: "frontend"}, false},
+		{"user deactivates own team", user, ActionTeamDeactivate, Resource{TeamName: "backend"}, false},
+
+		{"lead sets SLA of own team", lead, ActionTeamSetSLA, Resource{TeamName: "backend"}, true},
+		{"lead sets SLA of other team", lead, ActionTeamSetSLA, Resource{TeamName: "frontend"}, false},
+		{"user sets SLA", user, ActionTeamSetSLA, Resource{TeamName: "backend"}, false},
+
+		{"lead rebalances own team", lead, ActionTeamRebalance, Resource{TeamName: "backend"}, true},
+		{"lead rebalances other team", lead, ActionTeamRebalance, Resource{TeamName: "frontend"}, false},
+		{"user rebalances", user, ActionTeamRebalance, Resource{TeamName: "backend"}, false},
+
+		{"user toggles self", user, ActionUserSetActive, Resource{UserID: "u1", TeamName: "backend"}, true},
+		{"user toggles teammate", user, ActionUserSetActive, Resource{UserID: "u2", TeamName: "backend"}, false},
+		{"lead toggles own member", lead, ActionUserSetActive, Resource{UserID: "u2", TeamName: "backend"}, true},
+		{"lead toggles other team member", lead, ActionUserSetActive, Resource{UserID: "u9", TeamName: "frontend"}, false},
+		{"admin toggles anyone", admin, ActionUserSetActive, Resource{UserID: "u9", TeamName: "frontend"}, true},
+
+		{"user edits own notifications", user, ActionUserSetNotify, Resource{UserID: "u1", TeamName: "backend"}, true},
+		{"user edits other notifications", user, ActionUserSetNotify, Resource{UserID: "u2", TeamName: "backend"}, false},
+
+		{"admin sets roles", admin, ActionUserSetRoles, Resource{UserID: "u1"}, true},
+		{"service sets roles", service, ActionUserSetRoles, Resource{UserID: "u1"}, true},
+		{"lead sets roles", lead, ActionUserSetRoles, Resource{UserID: "u1"}, false},
+		{"user grants self", user, ActionUserSetRoles, Resource{UserID: "u1"}, false},
+
+		{"user opens own PR", user, ActionPullRequestCreate, Resource{AuthorID: "u1", TeamName: "backend"}, true},
+		{"user opens PR as teammate", user, ActionPullRequestCreate, Resource{AuthorID: "u2", TeamName: "backend"}, false},
+		{"lead opens PR for own member", lead, ActionPullRequestCreate, Resource{AuthorID: "u2", TeamName: "backend"}, true},
+		{"lead opens PR for other team", lead, ActionPullRequestCreate, Resource{AuthorID: "u9", TeamName: "frontend"}, false},
+		{"lead without team opens PR for unknown author", leadNoTeam, ActionPullRequestCreate, Resource{AuthorID: "u9"}, false},
+		{"admin opens PR for anyone", admin, ActionPullRequestCreate, Resource{AuthorID: "u9", TeamName: "frontend"}, true},
+		{"service opens PR for anyone", service, ActionPullRequestCreate, Resource{AuthorID: "u9"}, true},
+
+		{"author reassigns", user, ActionPullRequestReassign, Resource{AuthorID: "u1", TeamName: "backend"}, true},
+		{"user reassigns teammate PR", user, ActionPullRequestReassign, Resource{AuthorID: "u2", TeamName: "backend"}, false},
+		{"lead reassigns own team PR", lead, ActionPullRequestReassign, Resource{AuthorID: "u2", TeamName: "backend"}, true},
+		{"lead reassigns other team PR", lead, ActionPullRequestReassign, Resource{AuthorID: "u9", TeamName: "frontend"}, false},
+		{"admin reassigns any PR", admin, ActionPullRequestReassign, Resource{AuthorID: "u9", TeamName: "frontend"}, true},
+
+		{"author merges", user, ActionPullRequestMerge, Resource{AuthorID: "u1"}, true},
+		{"non-author merges", user, ActionPullRequestMerge, Resource{AuthorID: "u2"}, false},
+		{"lead merges teammate PR", lead, ActionPullRequestMerge, Resource{AuthorID: "u1"}, false},
+		{"admin merges any PR", admin, ActionPullRequestMerge, Resource{AuthorID: "u2"}, true},
+		{"empty identity never matches author", Subject{}, ActionPullRequestMerge, Resource{}, false},
+
+		{"admin exports snapshot", admin, ActionSnapshotExport, Resource{}, true},
+		{"lead exports snapshot", lead, ActionSnapshotExport, Resource{}, false},
+		{"service restores snapshot", service, ActionSnapshotRestore, Resource{}, true},
+		{"lead restores snapshot", lead, ActionSnapshotRestore, Resource{}, false},
+		{"admin reads audit", admin, ActionAuditRead, Resource{}, true},
+		{"service reads audit", service, ActionAuditRead, Resource{}, true},
+		{"lead reads audit", lead, ActionAuditRead, Resource{}, false},
+		{"user reads audit", user, ActionAuditRead, Resource{}, false},
+		{"admin reads contacts", admin, ActionUserContacts, Resource{}, true},
+		{"lead reads contacts", lead, ActionUserContacts, Resource{}, false},
+
+		{"unknown action denied", user, Action("team:delete"), Resource{TeamName: "backend"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Authorize(tt.sub, tt.act, tt.res)
+			if tt.allow {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrForbidden)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package sla
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"Backend-trainee-assignment-autumn-2025/internal/jobs"
+)
+
+// Calendar describes working time: weekdays between DayStart and DayEnd,
+// both offsets from midnight UTC.
+type Calendar struct {
+	DayStart time.Duration
+	DayEnd   time.Duration
+}
+
+var DefaultCalendar = Calendar{DayStart: 9 * time.Hour, DayEnd: 18 * time.Hour}
+
+// ParseCalendar parses a working day in the "HH:MM-HH:MM" form.
+func ParseCalendar(s string) (Calendar, error) {
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return Calendar{}, fmt.Errorf("working day %q: want HH:MM-HH:MM", s)
+	}
+	start, err := jobs.ParseTimeOfDay(strings.TrimSpace(from))
+	if err != nil {
+		return Calendar{}, err
+	}
+	end, err := jobs.ParseTimeOfDay(strings.TrimSpace(to))
+	if err != nil {
+		return Calendar{}, err
+	}
+	if end <= start {
+		return Calendar{}, fmt.Errorf("working day %q: end must be after start", s)
+	}
+	return Calendar{DayStart: start, DayEnd: end}, nil
+}
+
+// Between returns the working time elapsed between from and to.
+func (c Calendar) Between(from, to time.Time) time.Duration {
+	from, to = from.UTC(), to.UTC()
+	if !to.After(from) {
+		return 0
+	}
+
+	var total time.Duration
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if wd := day.Weekday(); wd == time.Saturday || wd == time.Sunday {
+			continue
+		}
+		start, end := day.Add(c.DayStart), day.Add(c.DayEnd)
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	return total
+}
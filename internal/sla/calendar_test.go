@@ -0,0 +1,52 @@
+package sla
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func at(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestCalendarBetween(t *testing.T) {
+	cal := DefaultCalendar // 09:00-18:00 UTC, Mon-Fri
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     time.Duration
+	}{
+		{"same day inside hours", "2025-10-20T10:00:00Z", "2025-10-20T12:30:00Z", 150 * time.Minute},
+		{"before and after hours", "2025-10-20T07:00:00Z", "2025-10-20T20:00:00Z", 9 * time.Hour},
+		{"overnight", "2025-10-20T17:00:00Z", "2025-10-21T10:00:00Z", 2 * time.Hour},
+		{"over weekend", "2025-10-24T17:00:00Z", "2025-10-27T10:00:00Z", 2 * time.Hour},
+		{"only weekend", "2025-10-25T09:00:00Z", "2025-10-26T18:00:00Z", 0},
+		{"full week", "2025-10-20T00:00:00Z", "2025-10-27T00:00:00Z", 45 * time.Hour},
+		{"reversed", "2025-10-21T10:00:00Z", "2025-10-20T10:00:00Z", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, cal.Between(at(tt.from), at(tt.to)))
+		})
+	}
+}
+
+func TestParseCalendar(t *testing.T) {
+	cal, err := ParseCalendar("08:30-17:00")
+	require.NoError(t, err)
+	require.Equal(t, 8*time.Hour+30*time.Minute, cal.DayStart)
+	require.Equal(t, 17*time.Hour, cal.DayEnd)
+
+	for _, bad := range []string{"", "09:00", "18:00-09:00", "9-18"} {
+		_, err := ParseCalendar(bad)
+		require.Error(t, err, bad)
+	}
+}
@@ -0,0 +1,145 @@
+package sla
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+	"Backend-trainee-assignment-autumn-2025/internal/notify"
+	"Backend-trainee-assignment-autumn-2025/internal/storage"
+)
+
+const (
+	PolicyNotify      = "notify"
+	PolicyAddReviewer = "add_reviewer"
+	PolicyReassign    = "reassign"
+)
+
+func ValidPolicy(p string) bool {
+	switch p {
+	case PolicyNotify, PolicyAddReviewer, PolicyReassign:
+		return true
+	}
+	return false
+}
+
+type Monitor struct {
+	store    *storage.Store
+	notifier *notify.Notifier
+	cal      Calendar
+	now      func() time.Time
+}
+
+func NewMonitor(st *storage.Store, n *notify.Notifier, cal Calendar) *Monitor {
+	return &Monitor{store: st, notifier: n, cal: cal, now: time.Now}
+}
+
+// Breaches lists assignments on OPEN pull requests that have been waiting
+// longer than their team's SLA, in working hours.
+func (m *Monitor) Breaches(ctx context.Context, teamName string) ([]models.SLABreach, error) {
+	assignments, err := m.store.GetSLAAssignments(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.now()
+	res := []models.SLABreach{}
+	for _, a := range assignments {
+		elapsed := m.cal.Between(a.AssignedAt, now)
+		if elapsed <= time.Duration(a.SLAHours)*time.Hour {
+			continue
+		}
+		a.ElapsedHours = float64(elapsed.Round(time.Minute)) / float64(time.Hour)
+		res = append(res, a)
+	}
+	return res, nil
+}
+
+// Run escalates every breach that has not been escalated yet according to
+// the team policy. It is meant to be called periodically.
+func (m *Monitor) Run(ctx context.Context) error {
+	breaches, err := m.Breaches(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	added := map[string]bool{}
+	for _, b := range breaches {
+		if b.EscalatedAt != nil {
+			continue
+		}
+		policy := policyFor(b, added)
+		if policy == PolicyAddReviewer {
+			added[b.PullRequestID] = true
+		}
+		if err := m.escalate(ctx, b, policy); err != nil {
+			slog.ErrorContext(ctx, "sla: escalate", "pull_request_id", b.PullRequestID, "reviewer_id", b.ReviewerID, "error", err)
+		}
+	}
+	return nil
+}
+
+// policyFor returns how to escalate b. add_reviewer grows a pull request
+// once: if an escalation already added a reviewer to it, earlier or in this
+// run (added), the breach is only notified, so the added reviewer breaching
+// in turn does not pull in the rest of the team.
+func policyFor(b models.SLABreach, added map[string]bool) string {
+	if b.Policy == PolicyAddReviewer && (b.ReviewerAdded || added[b.PullRequestID]) {
+		return PolicyNotify
+	}
+	return b.Policy
+}
+
+func (m *Monitor) escalate(ctx context.Context, b models.SLABreach, policy string) error {
+	switch policy {
+	case PolicyReassign:
+		pr, newID, err := m.store.ReassignReviewer(ctx, b.PullRequestID, b.ReviewerID, storage.ReasonSLA, false)
+		if err == nil {
+			m.notifier.ReviewersAssigned(pr, []string{newID})
+			return nil
+		}
+		if err != storage.ErrNoCandidate {
+			return err
+		}
+		slog.WarnContext(ctx, "sla: no replacement, notifying instead", "pull_request_id", b.PullRequestID, "reviewer_id", b.ReviewerID)
+	case PolicyAddReviewer:
+		pr, newID, err := m.store.AddReviewer(ctx, b.PullRequestID, storage.ReasonSLA)
+		switch err {
+		case nil:
+			m.notifier.ReviewersAssigned(pr, []string{newID})
+		case storage.ErrNoCandidate:
+			slog.WarnContext(ctx, "sla: no extra reviewer available", "pull_request_id", b.PullRequestID)
+		default:
+			return err
+		}
+	}
+
+	m.notifyBreach(ctx, b)
+	return m.store.MarkSLAEscalated(ctx, b.PullRequestID, b.ReviewerID)
+}
+
+func (m *Monitor) notifyBreach(ctx context.Context, b models.SLABreach) {
+	if m.notifier == nil {
+		return
+	}
+	to := []string{}
+	for _, uid := range []string{b.ReviewerID, b.AuthorID} {
+		u, err := m.store.GetUser(ctx, uid)
+		if err != nil {
+			slog.ErrorContext(ctx, "sla: lookup user", "user_id", uid, "error", err)
+			continue
+		}
+		if u.Email != "" {
+			to = append(to, u.Email)
+		}
+	}
+	if len(to) == 0 {
+		return
+	}
+
+	msg := notify.SLABreachMessage(b, to)
+	if err := m.notifier.Send(ctx, msg); err != nil {
+		slog.ErrorContext(ctx, "sla: send breach email", "pull_request_id", b.PullRequestID, "error", err)
+	}
+}
@@ -0,0 +1,29 @@
+package sla
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+)
+
+func TestPolicyFor(t *testing.T) {
+	tests := []struct {
+		name   string
+		breach models.SLABreach
+		added  map[string]bool
+		want   string
+	}{
+		{"notify", models.SLABreach{PullRequestID: "pr-1", Policy: PolicyNotify}, nil, PolicyNotify},
+		{"reassign", models.SLABreach{PullRequestID: "pr-1", Policy: PolicyReassign, ReviewerAdded: true}, nil, PolicyReassign},
+		{"first breach adds a reviewer", models.SLABreach{PullRequestID: "pr-1", Policy: PolicyAddReviewer}, map[string]bool{"pr-2": true}, PolicyAddReviewer},
+		{"second breach after an earlier run", models.SLABreach{PullRequestID: "pr-1", Policy: PolicyAddReviewer, ReviewerAdded: true}, nil, PolicyNotify},
+		{"second breach in the same run", models.SLABreach{PullRequestID: "pr-1", Policy: PolicyAddReviewer}, map[string]bool{"pr-1": true}, PolicyNotify},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, policyFor(tt.breach, tt.added))
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+)
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+func (s *Store) CreateAPIKey(ctx context.Context, name string, scopes []string, hash string) (models.APIKey, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return models.APIKey{}, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return models.APIKey{}, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	k := models.APIKey{ID: hex.EncodeToString(b), Name: name, Scopes: scopes}
+	err = tx.QueryRow(ctx,
+		`INSERT INTO api_keys(id, name, key_hash, scopes)
+         VALUES($1,$2,$3,$4)
+         RETURNING created_at`,
+		k.ID, k.Name, hash, k.Scopes,
+	).Scan(&k.CreatedAt)
+	if err != nil {
+		return models.APIKey{}, err
+	}
+
+	if err := writeAudit(ctx, tx, "api_key.create", "api_key", k.ID, nil, k); err != nil {
+		return models.APIKey{}, err
+	}
+
+	return k, tx.Commit(ctx)
+}
+
+func (s *Store) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	rows, err := s.db.Query(ctx,
+		`SELECT id, name, scopes, created_at, last_used_at, revoked_at
+         FROM api_keys
+         ORDER BY created_at`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []models.APIKey{}
+	for rows.Next() {
+		var k models.APIKey
+		if err := rows.Scan(&k.ID, &k.Name, &k.Scopes, &k.CreatedAt, &k.LastUsedAt, &k.RevokedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, k)
+	}
+	return res, rows.Err()
+}
+
+func (s *Store) RevokeAPIKey(ctx context.Context, id string) error {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var revokedAt *time.Time
+	err = tx.QueryRow(ctx,
+		`SELECT revoked_at FROM api_keys WHERE id=$1 FOR UPDATE`,
+		id,
+	).Scan(&revokedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if revokedAt != nil {
+		return nil
+	}
+
+	err = tx.QueryRow(ctx,
+		`UPDATE api_keys SET revoked_at=now() WHERE id=$1 RETURNING revoked_at`,
+		id,
+	).Scan(&revokedAt)
+	if err != nil {
+		return err
+	}
+
+	err = writeAudit(ctx, tx, "api_key.revoke", "api_key", id,
+		nil, map[string]time.Time{"revoked_at": *revokedAt})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *Store) LookupAPIKey(ctx context.Context, hash string) (models.APIKey, bool, error) {
+	var k models.APIKey
+	err := s.db.QueryRow(ctx,
+		`SELECT id, name, scopes, created_at, last_used_at
+         FROM api_keys
+         WHERE key_hash=$1 AND revoked_at IS NULL`,
+		hash,
+	).Scan(&k.ID, &k.Name, &k.Scopes, &k.CreatedAt, &k.LastUsedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return k, false, nil
+	}
+	if err != nil {
+		return k, false, err
+	}
+	return k, true, nil
+}
+
+func (s *Store) TouchAPIKey(ctx context.Context, id string) error {
+	_, err := s.db.Exec(ctx,
+		`UPDATE api_keys SET last_used_at=now() WHERE id=$1`,
+		id,
+	)
+	return err
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"Backend-trainee-assignment-autumn-2025/internal/auth"
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+)
+
+// querier is satisfied by both the pool and a transaction, so read helpers
+// can take before/after snapshots inside the mutating transaction.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+type AuditFilter struct {
+	Actor      string
+	Action     string
+	EntityType string
+	EntityID   string
+	From       *time.Time
+	To         *time.Time
+	// BeforeID pages backwards: only entries with a smaller id are returned.
+	BeforeID int64
+	Limit    int
+}
+
+func actorFrom(ctx context.Context) string {
+	if p, ok := auth.FromContext(ctx); ok && p.Subject != "" {
+		return p.Subject
+	}
+	return "anonymous"
+}
+
+// writeAudit appends an entry to audit_log. It must run in the same
+// transaction as the change it records. nil before/after are stored as NULL.
+func writeAudit(ctx context.Context, q querier, action, entityType, entityID string, before, after interface{}) error {
+	b, err := auditJSON(before)
+	if err != nil {
+		return err
+	}
+	a, err := auditJSON(after)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(ctx,
+		`INSERT INTO audit_log(actor, action, entity_type, entity_id, before, after)
+         VALUES($1,$2,$3,$4,$5,$6)`,
+		actorFrom(ctx), action, entityType, entityID, b, a,
+	)
+	return err
+}
+
+func auditJSON(v interface{}) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+func (s *Store) ListAudit(ctx context.Context, f AuditFilter) ([]models.AuditEntry, error) {
+	rows, err := s.db.Query(ctx,
+		`SELECT id, created_at, actor, action, entity_type, entity_id, before, after
+         FROM audit_log
+         WHERE ($1 = '' OR actor = $1)
+           AND ($2 = '' OR action = $2)
+           AND ($3 = '' OR entity_type = $3)
+           AND ($4 = '' OR entity_id = $4)
+           AND ($5::timestamptz IS NULL OR created_at >= $5)
+           AND ($6::timestamptz IS NULL OR created_at < $6)
+           AND ($7 = 0 OR id < $7)
+         ORDER BY id DESC
+         LIMIT $8`,
+		f.Actor, f.Action, f.EntityType, f.EntityID, f.From, f.To, f.BeforeID, f.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []models.AuditEntry{}
+	for rows.Next() {
+		var e models.AuditEntry
+		var before, after []byte
+		if err := rows.Scan(&e.ID, &e.CreatedAt, &e.Actor, &e.Action, &e.EntityType, &e.EntityID, &before, &after); err != nil {
+			return nil, err
+		}
+		if before != nil {
+			e.Before = json.RawMessage(before)
+		}
+		if after != nil {
+			e.After = json.RawMessage(after)
+		}
+		res = append(res, e)
+	}
+	return res, rows.Err()
+}
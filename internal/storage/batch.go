@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"Backend-trainee-assignment-autumn-2025/internal/metrics"
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+)
+
+// PRCreateResult is the outcome of one pull request passed to CreatePRs:
+// the created PR with its reviewers, or ErrPRExists / ErrUserNotFound.
+type PRCreateResult struct {
+	PR  models.PullRequest
+	Err error
+}
+
+// CreatePRs creates pull requests in bulk and assigns reviewers to each the
+// way CreatePR does. Items whose ID already exists (or repeats an earlier
+// item) or whose author is unknown fail on their own; the rest are written
+// with COPY in a single transaction. Results are in input order.
+//
+// If a PR with one of the IDs is created concurrently, the whole batch
+// fails with ErrPRExists and nothing is written.
+func (s *Store) CreatePRs(ctx context.Context, prs []models.PullRequest) ([]PRCreateResult, error) {
+	results := make([]PRCreateResult, len(prs))
+	if len(prs) == 0 {
+		return results, nil
+	}
+	ids := make([]string, len(prs))
+	authors := make([]string, len(prs))
+	for i, pr := range prs {
+		results[i].PR = pr
+		ids[i], authors[i] = pr.PullRequestID, pr.AuthorID
+	}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	taken := make(map[string]bool)
+	rows, err := tx.Query(ctx,
+		`SELECT pull_request_id FROM pull_requests WHERE pull_request_id = ANY($1)`, ids)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range existing {
+		taken[id] = true
+	}
+
+	teamOf := make(map[string]string)
+	rows, err = tx.Query(ctx, `SELECT user_id, team_name FROM users WHERE user_id = ANY($1)`, authors)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var uid, team string
+		if err := rows.Scan(&uid, &team); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		teamOf[uid] = team
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	teams := make([]string, 0, len(teamOf))
+	for _, team := range teamOf {
+		teams = append(teams, team)
+	}
+	activeIn := make(map[string][]string)
+	rows, err = tx.Query(ctx,
+		`SELECT team_name, user_id FROM users WHERE team_name = ANY($1) AND is_active = true`, teams)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var team, uid string
+		if err := rows.Scan(&team, &uid); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		activeIn[team] = append(activeIn[team], uid)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var now time.Time
+	if err := tx.QueryRow(ctx, `SELECT now()`).Scan(&now); err != nil {
+		return nil, err
+	}
+	actor := actorFrom(ctx)
+
+	var prRows, reviewerRows, historyRows, auditRows [][]interface{}
+	for i := range results {
+		r := &results[i]
+		team, ok := teamOf[r.PR.AuthorID]
+		switch {
+		case taken[r.PR.PullRequestID]:
+			r.Err = ErrPRExists
+			continue
+		case !ok:
+			r.Err = ErrUserNotFound
+			continue
+		}
+		taken[r.PR.PullRequestID] = true
+
+		r.PR.Status = "OPEN"
+		r.PR.CreatedAt = &now
+		r.PR.AssignedReviewers = pickReviewers(activeIn[team], r.PR.AuthorID, s.reviewersPerPR)
+		prRows = append(prRows, []interface{}{r.PR.PullRequestID, r.PR.PullRequestName, r.PR.AuthorID, r.PR.Status, now, now})
+		for _, uid := range r.PR.AssignedReviewers {
+			reviewerRows = append(reviewerRows, []interface{}{r.PR.PullRequestID, uid, now})
+			historyRows = append(historyRows, []interface{}{r.PR.PullRequestID, EventAssigned, uid, ReasonInitial, actor, now})
+		}
+		after, err := auditJSON(r.PR)
+		if err != nil {
+			return nil, err
+		}
+		auditRows = append(auditRows, []interface{}{now, actor, "pr.create", "pull_request", r.PR.PullRequestID, after})
+	}
+	if len(prRows) == 0 {
+		return results, nil
+	}
+
+	copies := []struct {
+		table   string
+		columns []string
+		rows    [][]interface{}
+	}{
+		{"pull_requests", []string{"pull_request_id", "pull_request_name", "author_id", "status", "created_at", "last_activity_at"}, prRows},
+		{"pr_reviewers", []string{"pull_request_id", "user_id", "assigned_at"}, reviewerRows},
+		{"assignment_history", []string{"pull_request_id", "event", "user_id", "reason", "actor", "created_at"}, historyRows},
+		{"audit_log", []string{"created_at", "actor", "action", "entity_type", "entity_id", "after"}, auditRows},
+	}
+	for _, c := range copies {
+		if len(c.rows) == 0 {
+			continue
+		}
+		_, err := tx.CopyFrom(ctx, pgx.Identifier{c.table}, c.columns, pgx.CopyFromRows(c.rows))
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrPRExists
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	for range prRows {
+		metrics.PRCreated()
+	}
+	return results, nil
+}
+
+// pickReviewers chooses up to n random members of candidates other than the
+// author, the same draw as CreatePR makes with ORDER BY random().
+func pickReviewers(candidates []string, authorID string, n int) []string {
+	picked := make([]string, 0, n)
+	for _, i := range rand.Perm(len(candidates)) {
+		if len(picked) == n {
+			break
+		}
+		if candidates[i] != authorID {
+			picked = append(picked, candidates[i])
+		}
+	}
+	// getPR lists reviewers assigned together in this order.
+	sort.Strings(picked)
+	return picked
+}
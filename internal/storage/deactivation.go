@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/jackc/pgx/v5"
+
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+)
+
+// Deactivation is the outcome of BulkDeactivateUsers. Added lists the
+// replacement reviewers; it is empty for a dry run.
+type Deactivation struct {
+	Result models.DeactivationResult
+	Added  []AddedReviewer
+}
+
+// AddedReviewer is a reviewer assigned by BulkDeactivateUsers; PR is the
+// pull request afterwards.
+type AddedReviewer struct {
+	PR     models.PullRequest
+	UserID string
+}
+
+// replaceDeactivated removes the users of res.Deactivated from the open
+// pull requests they review, fills in res.PullRequests and finds a
+// replacement among the active members of teamName for each pull request
+// left without reviewers. Other reviewers are left alone. It returns the
+// reviewers it assigned.
+func replaceDeactivated(ctx context.Context, tx pgx.Tx, teamName string, res *models.DeactivationResult) ([]AddedReviewer, error) {
+	gone := map[string]bool{}
+	for _, uid := range res.Deactivated {
+		gone[uid] = true
+	}
+
+	activeRows, err := tx.Query(ctx,
+		`SELECT user_id FROM users
+         WHERE team_name=$1 AND is_active=true
+         ORDER BY user_id`,
+		teamName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var active []string
+	var uid string
+	if err := streamRows(activeRows, []interface{}{&uid}, func() error {
+		active = append(active, uid)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	prRows, err := tx.Query(ctx,
+		`SELECT pr.pull_request_id, pr.author_id, array_agg(r.user_id ORDER BY r.user_id)
+         FROM pull_requests pr
+         JOIN pr_reviewers r ON r.pull_request_id = pr.pull_request_id
+         WHERE pr.status='OPEN'
+           AND EXISTS (SELECT 1 FROM pr_reviewers d
+                       WHERE d.pull_request_id = pr.pull_request_id AND d.user_id = ANY($1))
+         GROUP BY pr.pull_request_id
+         ORDER BY pr.pull_request_id`,
+		res.Deactivated,
+	)
+	if err != nil {
+		return nil, err
+	}
+	type openPR struct {
+		ID        string
+		Author    string
+		Reviewers []string
+	}
+	var prs []openPR
+	var pr openPR
+	if err := streamRows(prRows, []interface{}{&pr.ID, &pr.Author, &pr.Reviewers}, func() error {
+		prs = append(prs, pr)
+		pr.Reviewers = nil
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	pick := func(candidates []string) string {
+		return candidates[rand.Intn(len(candidates))]
+	}
+	var added []AddedReviewer
+	for _, pr := range prs {
+		change, final := replaceReviewers(pr.ID, pr.Author, teamName, pr.Reviewers, gone, active, pick)
+		if _, err := tx.Exec(ctx,
+			`DELETE FROM pr_reviewers
+             WHERE pull_request_id=$1 AND user_id = ANY($2)`,
+			pr.ID, change.RemovedReviewers,
+		); err != nil {
+			return nil, err
+		}
+		for _, r := range change.RemovedReviewers {
+			if err := recordAssignment(ctx, tx, pr.ID, EventUnassigned, r, "", ReasonDeactivation); err != nil {
+				return nil, err
+			}
+		}
+		if change.AddedReviewer != "" {
+			if _, err := tx.Exec(ctx,
+				`INSERT INTO pr_reviewers(pull_request_id, user_id)
+                 VALUES($1,$2)`,
+				pr.ID, change.AddedReviewer,
+			); err != nil {
+				return nil, err
+			}
+			if err := recordAssignment(ctx, tx, pr.ID, EventAssigned, change.AddedReviewer, "", ReasonDeactivation); err != nil {
+				return nil, err
+			}
+		}
+		if err := touchPR(ctx, tx, pr.ID); err != nil {
+			return nil, err
+		}
+		err := writeAudit(ctx, tx, "pr.replace_reviewers", "pull_request", pr.ID,
+			map[string][]string{"assigned_reviewers": pr.Reviewers},
+			map[string][]string{"assigned_reviewers": final})
+		if err != nil {
+			return nil, err
+		}
+		res.PullRequests = append(res.PullRequests, change)
+		if change.AddedReviewer != "" {
+			after, err := getPR(ctx, tx, pr.ID)
+			if err != nil {
+				return nil, err
+			}
+			added = append(added, AddedReviewer{PR: after, UserID: change.AddedReviewer})
+		}
+	}
+	return added, nil
+}
+
+// replaceReviewers works out how the reviewers of one open pull request
+// change when the users in gone are deactivated. They are removed; if no
+// reviewer is left, pick chooses a replacement among candidates who are
+// neither the author nor already reviewing, and "" from pick leaves the
+// pull request without reviewers. It returns the change and the new
+// reviewers.
+func replaceReviewers(prID, authorID, teamName string, reviewers []string, gone map[string]bool, candidates []string, pick func([]string) string) (models.ReviewerChange, []string) {
+	change := models.ReviewerChange{PullRequestID: prID, RemovedReviewers: []string{}}
+	taken := map[string]bool{}
+	final := []string{}
+	for _, r := range reviewers {
+		taken[r] = true
+		if gone[r] {
+			change.RemovedReviewers = append(change.RemovedReviewers, r)
+		} else {
+			final = append(final, r)
+		}
+	}
+	if len(final) > 0 || len(change.RemovedReviewers) == 0 {
+		return change, final
+	}
+
+	eligible := []string{}
+	for _, u := range candidates {
+		if u != authorID && !taken[u] && !gone[u] {
+			eligible = append(eligible, u)
+		}
+	}
+	if len(eligible) > 0 {
+		change.AddedReviewer = pick(eligible)
+	}
+	if change.AddedReviewer == "" {
+		change.LeftWithoutReviewers = true
+		change.Warning = "no active member of team " + teamName + " can review this PR; it has no reviewers"
+		return change, final
+	}
+	return change, append(final, change.AddedReviewer)
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+)
+
+func first(candidates []string) string { return candidates[0] }
+
+func TestReplaceReviewers(t *testing.T) {
+	gone := map[string]bool{"u2": true, "u3": true}
+	tests := []struct {
+		name       string
+		reviewers  []string
+		candidates []string
+		pick       func([]string) string
+		want       models.ReviewerChange
+		final      []string
+	}{
+		{
+			name:       "another reviewer remains",
+			reviewers:  []string{"u2", "u4"},
+			candidates: []string{"u4", "u5"},
+			want:       models.ReviewerChange{RemovedReviewers: []string{"u2"}},
+			final:      []string{"u4"},
+		},
+		{
+			name:       "replacement skips author, reviewers and deactivated users",
+			reviewers:  []string{"u2", "u3"},
+			candidates: []string{"u1", "u2", "u3", "u5"},
+			want:       models.ReviewerChange{RemovedReviewers: []string{"u2", "u3"}, AddedReviewer: "u5"},
+			final:      []string{"u5"},
+		},
+		{
+			name:       "no candidate",
+			reviewers:  []string{"u2"},
+			candidates: []string{"u1"},
+			want: models.ReviewerChange{RemovedReviewers: []string{"u2"}, LeftWithoutReviewers: true,
+				Warning: "no active member of team backend can review this PR; it has no reviewers"},
+			final: []string{},
+		},
+		{
+			name:       "pick declines",
+			reviewers:  []string{"u2"},
+			candidates: []string{"u5"},
+			pick:       func([]string) string { return "" },
+			want: models.ReviewerChange{RemovedReviewers: []string{"u2"}, LeftWithoutReviewers: true,
+				Warning: "no active member of team backend can review this PR; it has no reviewers"},
+			final: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pick := tt.pick
+			if pick == nil {
+				pick = first
+			}
+			change, final := replaceReviewers("pr-1", "u1", "backend", tt.reviewers, gone, tt.candidates, pick)
+			tt.want.PullRequestID = "pr-1"
+			require.Equal(t, tt.want, change)
+			require.Equal(t, tt.final, final)
+		})
+	}
+}
+
+// A team lead deactivating members of their team must not touch reviewers
+// of other teams, even on a pull request that also had a deactivated one.
+func TestReplaceReviewersKeepsOtherReviewers(t *testing.T) {
+	gone := map[string]bool{"u2": true}
+
+	change, final := replaceReviewers("pr-1", "u1", "backend", []string{"f1", "f2"}, gone, []string{"u3"}, first)
+	require.Empty(t, change.RemovedReviewers)
+	require.Empty(t, change.AddedReviewer)
+	require.False(t, change.LeftWithoutReviewers)
+	require.Equal(t, []string{"f1", "f2"}, final)
+
+	change, final = replaceReviewers("pr-2", "u1", "backend", []string{"f1", "u2"}, gone, []string{"u3"}, first)
+	require.Equal(t, []string{"u2"}, change.RemovedReviewers)
+	require.Empty(t, change.AddedReviewer)
+	require.Equal(t, []string{"f1"}, final)
+}
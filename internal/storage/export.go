@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+)
+
+// ExportFilter narrows an export. TeamName is the user's team, or the PR
+// author's team for pull requests and their reviewers. From (inclusive) and
+// To (exclusive) bound PR creation and reviewer assignment times; users
+// have no timestamp and ignore them.
+type ExportFilter struct {
+	TeamName string
+	From     *time.Time
+	To       *time.Time
+}
+
+// ExportUsers calls fn for every user matching f, ordered by team and ID.
+// Rows are streamed from the database, not collected, so fn sees each one
+// as it arrives; an error from fn stops the export and is returned.
+func (s *Store) ExportUsers(ctx context.Context, f ExportFilter, fn func(models.User) error) error {
+	rows, err := s.db.Query(ctx,
+		`SELECT user_id, username, team_name, is_active, COALESCE(email, ''), daily_digest
+         FROM users
+         WHERE ($1 = '' OR team_name = $1)
+         ORDER BY team_name, user_id`,
+		f.TeamName,
+	)
+	if err != nil {
+		return err
+	}
+	var u models.User
+	return streamRows(rows, []interface{}{&u.UserID, &u.Username, &u.TeamName, &u.IsActive, &u.Email, &u.DailyDigest},
+		func() error { return fn(u) })
+}
+
+// ExportPRs calls fn for every pull request matching f in creation order.
+func (s *Store) ExportPRs(ctx context.Context, f ExportFilter, fn func(models.PRRecord) error) error {
+	rows, err := s.db.Query(ctx,
+		`SELECT p.pull_request_id, p.pull_request_name, p.author_id, a.team_name, p.status,
+                p.created_at, p.merged_at, p.stale_at, p.closed_at
+         FROM pull_requests p
+         JOIN users a ON a.user_id = p.author_id
+         WHERE ($1 = '' OR a.team_name = $1)
+           AND ($2::timestamptz IS NULL OR p.created_at >= $2)
+           AND ($3::timestamptz IS NULL OR p.created_at < $3)
+         ORDER BY p.created_at, p.pull_request_id`,
+		f.TeamName, f.From, f.To,
+	)
+	if err != nil {
+		return err
+	}
+	var p models.PRRecord
+	return streamRows(rows, []interface{}{&p.PullRequestID, &p.PullRequestName, &p.AuthorID, &p.TeamName, &p.Status,
+		&p.CreatedAt, &p.MergedAt, &p.StaleAt, &p.ClosedAt},
+		func() error { return fn(p) })
+}
+
+// ExportReviewers calls fn for every current reviewer assignment matching f
+// in assignment order.
+func (s *Store) ExportReviewers(ctx context.Context, f ExportFilter, fn func(models.ReviewerRecord) error) error {
+	rows, err := s.db.Query(ctx,
+		`SELECT r.pull_request_id, r.user_id, a.team_name, r.assigned_at, r.sla_escalated_at
+         FROM pr_reviewers r
+         JOIN pull_requests p ON p.pull_request_id = r.pull_request_id
+         JOIN users a ON a.user_id = p.author_id
+         WHERE ($1 = '' OR a.team_name = $1)
+           AND ($2::timestamptz IS NULL OR r.assigned_at >= $2)
+           AND ($3::timestamptz IS NULL OR r.assigned_at < $3)
+         ORDER BY r.assigned_at, r.pull_request_id, r.user_id`,
+		f.TeamName, f.From, f.To,
+	)
+	if err != nil {
+		return err
+	}
+	var rr models.ReviewerRecord
+	return streamRows(rows, []interface{}{&rr.PullRequestID, &rr.UserID, &rr.TeamName, &rr.AssignedAt, &rr.SLAEscalatedAt},
+		func() error { return fn(rr) })
+}
+
+// streamRows scans each row into dest and calls emit, closing rows when
+// done.
+func streamRows(rows pgx.Rows, dest []interface{}, emit func() error) error {
+	defer rows.Close()
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		if err := emit(); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"Backend-trainee-assignment-autumn-2025/internal/metrics"
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+)
+
+// TeamLoad returns the active members of teamName with the assignments they
+// received in [from, to), either bound optional, and the open reviews they
+// hold now.
+func (s *Store) TeamLoad(ctx context.Context, teamName string, from, to *time.Time) ([]models.MemberLoad, error) {
+	return teamLoad(ctx, s.db, teamName, from, to)
+}
+
+func teamLoad(ctx context.Context, q querier, teamName string, from, to *time.Time) ([]models.MemberLoad, error) {
+	var exists bool
+	err := q.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM teams WHERE team_name=$1)`,
+		teamName,
+	).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrTeamNotFound
+	}
+
+	rows, err := q.Query(ctx,
+		`SELECT u.user_id, u.username,
+                (SELECT COUNT(*)
+                 FROM assignment_history h
+                 WHERE h.user_id = u.user_id
+                   AND h.event IN ('assigned', 'replaced')
+                   AND ($2::timestamptz IS NULL OR h.created_at >= $2)
+                   AND ($3::timestamptz IS NULL OR h.created_at < $3)),
+                (SELECT COUNT(*)
+                 FROM pr_reviewers r
+                 JOIN pull_requests p ON p.pull_request_id = r.pull_request_id
+                 WHERE r.user_id = u.user_id AND p.status = 'OPEN')
+         FROM users u
+         WHERE u.team_name = $1 AND u.is_active = true
+         ORDER BY u.user_id`,
+		teamName, from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	members := []models.MemberLoad{}
+	var m models.MemberLoad
+	err = streamRows(rows, []interface{}{&m.UserID, &m.Username, &m.Assignments, &m.OpenAssignments},
+		func() error { members = append(members, m); return nil })
+	return members, err
+}
+
+// Reassignment is one reviewer replaced by RebalanceTeam; PR is the pull
+// request afterwards.
+type Reassignment struct {
+	PR        models.PullRequest
+	OldUserID string
+	NewUserID string
+}
+
+// Rebalance is the outcome of RebalanceTeam: the open reviews each active
+// member held before and after, and the moves in the order they were made.
+type Rebalance struct {
+	Before map[string]int
+	After  map[string]int
+	Moves  []Reassignment
+}
+
+// RebalanceTeam moves open reviews between teamName's active members, one at
+// a time from the most to the least loaded, until their open loads differ by
+// at most one, no further move is allowed, or maxMoves were made. Every move
+// follows the ReassignReviewer rules and is recorded with ReasonCapacity;
+// they are committed together.
+func (s *Store) RebalanceTeam(ctx context.Context, teamName string, maxMoves int) (Rebalance, error) {
+	var res Rebalance
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return res, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	members, err := teamLoad(ctx, tx, teamName, nil, nil)
+	if err != nil {
+		return res, err
+	}
+	res.Before = make(map[string]int, len(members))
+	load := make(map[string]int, len(members))
+	ids := make([]string, len(members))
+	for i, m := range members {
+		res.Before[m.UserID] = m.OpenAssignments
+		load[m.UserID] = m.OpenAssignments
+		ids[i] = m.UserID
+	}
+
+	for len(res.Moves) < maxMoves {
+		sort.Slice(ids, func(i, j int) bool {
+			if load[ids[i]] != load[ids[j]] {
+				return load[ids[i]] > load[ids[j]]
+			}
+			return ids[i] < ids[j]
+		})
+		mv, err := rebalanceStep(ctx, tx, ids, load)
+		if err != nil {
+			return res, err
+		}
+		if mv == nil {
+			break
+		}
+		load[mv.OldUserID]--
+		load[mv.NewUserID]++
+		res.Moves = append(res.Moves, *mv)
+	}
+	res.After = load
+
+	if err := tx.Commit(ctx); err != nil {
+		return res, err
+	}
+	for range res.Moves {
+		metrics.Reassigned(ReasonCapacity)
+	}
+	return res, nil
+}
+
+// rebalanceStep makes the first allowed move from a member of ids (sorted by
+// load, highest first) to one at least two reviews lighter, trying the
+// lightest first. It returns nil when there is none.
+func rebalanceStep(ctx context.Context, tx pgx.Tx, ids []string, load map[string]int) (*Reassignment, error) {
+	for _, from := range ids {
+		for j := len(ids) - 1; j >= 0; j-- {
+			to := ids[j]
+			if load[from]-load[to] < 2 {
+				break
+			}
+			// The most recently assigned review is the least likely to
+			// have been started.
+			var prID string
+			err := tx.QueryRow(ctx,
+				`SELECT p.pull_request_id
+                 FROM pr_reviewers r
+                 JOIN pull_requests p ON p.pull_request_id = r.pull_request_id
+                 WHERE r.user_id = $1 AND p.status = 'OPEN' AND p.author_id <> $2
+                   AND NOT EXISTS (
+                       SELECT 1 FROM pr_reviewers o
+                       WHERE o.pull_request_id = p.pull_request_id AND o.user_id = $2
+                   )
+                 ORDER BY r.assigned_at DESC, p.pull_request_id
+                 LIMIT 1`,
+				from, to,
+			).Scan(&prID)
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			pr, _, err := reassign(ctx, tx, prID, from, ReasonCapacity, func(candidates []string) string {
+				for _, c := range candidates {
+					if c == to {
+						return c
+					}
+				}
+				return ""
+			})
+			if err == ErrNoCandidate {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			return &Reassignment{PR: pr, OldUserID: from, NewUserID: to}, nil
+		}
+	}
+	return nil, nil
+}
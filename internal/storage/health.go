@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"Backend-trainee-assignment-autumn-2025/migrations"
+)
+
+// SchemaVersion is the schema revision this build expects. Bump it together
+// with the INSERT INTO schema_version at the end of migrations/init.sql.
+const SchemaVersion = 2
+
+// migrateLockID is the advisory lock that keeps instances starting at the
+// same time from applying the schema concurrently.
+const migrateLockID = 7_281_025
+
+// Migrate applies migrations/init.sql in one transaction. The file only
+// creates what is missing, so this brings an older database up to
+// SchemaVersion and leaves a current one as it is.
+func (s *Store) Migrate(ctx context.Context) error {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, migrateLockID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, migrations.Schema); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (s *Store) Ping(ctx context.Context) error {
+	return s.db.Ping(ctx)
+}
+
+// AppliedSchemaVersion returns the newest schema revision recorded in the
+// database, or 0 when none is.
+func (s *Store) AppliedSchemaVersion(ctx context.Context) (int, error) {
+	var v int
+	err := s.db.QueryRow(ctx,
+		`SELECT COALESCE(MAX(version), 0) FROM schema_version`,
+	).Scan(&v)
+	return v, err
+}
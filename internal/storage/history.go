@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+)
+
+// Assignment history events.
+const (
+	EventAssigned   = "assigned"
+	EventUnassigned = "unassigned"
+	EventReplaced   = "replaced"
+)
+
+// Reasons a reviewer was assigned or removed.
+const (
+	ReasonInitial        = "initial"
+	ReasonManualReassign = "manual_reassign"
+	ReasonDeactivation   = "deactivation"
+	ReasonSLA            = "sla"
+	ReasonCapacity       = "capacity"
+)
+
+// recordAssignment appends to assignment_history. prevUserID is only set for
+// replaced events.
+func recordAssignment(ctx context.Context, q querier, prID, event, userID, prevUserID, reason string) error {
+	var prev *string
+	if prevUserID != "" {
+		prev = &prevUserID
+	}
+	_, err := q.Exec(ctx,
+		`INSERT INTO assignment_history(pull_request_id, event, user_id, previous_user_id, reason, actor)
+         VALUES($1,$2,$3,$4,$5,$6)`,
+		prID, event, userID, prev, reason, actorFrom(ctx),
+	)
+	return err
+}
+
+// GetPRTimeline returns the assignment events of a pull request in the order
+// they happened.
+func (s *Store) GetPRTimeline(ctx context.Context, prID string) ([]models.AssignmentEvent, error) {
+	var exists bool
+	err := s.db.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id=$1)`,
+		prID,
+	).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrPRNotFound
+	}
+
+	rows, err := s.db.Query(ctx,
+		`SELECT id, pull_request_id, event, user_id, COALESCE(previous_user_id, ''), reason, actor, created_at
+         FROM assignment_history
+         WHERE pull_request_id=$1
+         ORDER BY id`,
+		prID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.AssignmentEvent{}
+	for rows.Next() {
+		var e models.AssignmentEvent
+		err := rows.Scan(&e.ID, &e.PullRequestID, &e.Event, &e.UserID, &e.PreviousUserID, &e.Reason, &e.Actor, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// idempotencyLease is how long a reserved key without a response blocks
+// retries. A request that ran this long without finishing is taken to have
+// died with its process, and the key is handed out again.
+const idempotencyLease = 5 * time.Minute
+
+// IdempotentRequest is what is stored under an Idempotency-Key.
+type IdempotentRequest struct {
+	RequestHash string
+	// Done is false while the first request is still being processed.
+	Done       bool
+	StatusCode int
+	Response   []byte
+}
+
+// ReserveIdempotencyKey claims key for the calling actor. It returns nil if
+// the key was free (or had expired, or its reservation outlived
+// idempotencyLease) and the caller should process the request, or the
+// request already stored under the key.
+func (s *Store) ReserveIdempotencyKey(ctx context.Context, key, requestHash string, ttl time.Duration) (*IdempotentRequest, error) {
+	actor := actorFrom(ctx)
+
+	var reserved bool
+	err := s.db.QueryRow(ctx,
+		`INSERT INTO idempotency_keys(actor, key, request_hash, expires_at)
+         VALUES ($1, $2, $3, now() + $4::interval)
+         ON CONFLICT (actor, key) DO UPDATE
+            SET request_hash = EXCLUDED.request_hash, status_code = NULL, response = NULL,
+                created_at = now(), expires_at = EXCLUDED.expires_at
+            WHERE idempotency_keys.expires_at <= now()
+               OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at <= now() - $5::interval)
+         RETURNING true`,
+		actor, key, requestHash, ttl, idempotencyLease,
+	).Scan(&reserved)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	var req IdempotentRequest
+	var status *int
+	err = s.db.QueryRow(ctx,
+		`SELECT request_hash, status_code, response FROM idempotency_keys
+         WHERE actor = $1 AND key = $2`,
+		actor, key,
+	).Scan(&req.RequestHash, &status, &req.Response)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Purged between the two statements; try again.
+		return s.ReserveIdempotencyKey(ctx, key, requestHash, ttl)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status != nil {
+		req.Done, req.StatusCode = true, *status
+	}
+	return &req, nil
+}
+
+// CompleteIdempotencyKey stores the response of a reserved key.
+func (s *Store) CompleteIdempotencyKey(ctx context.Context, key string, status int, response []byte) error {
+	_, err := s.db.Exec(ctx,
+		`UPDATE idempotency_keys SET status_code = $3, response = $4
+         WHERE actor = $1 AND key = $2`,
+		actorFrom(ctx), key, status, response,
+	)
+	return err
+}
+
+// ReleaseIdempotencyKey forgets a reserved key so the request can be retried,
+// used when processing failed with a server error or a panic.
+func (s *Store) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	_, err := s.db.Exec(ctx,
+		`DELETE FROM idempotency_keys WHERE actor = $1 AND key = $2 AND status_code IS NULL`,
+		actorFrom(ctx), key,
+	)
+	return err
+}
+
+// PurgeIdempotencyKeys deletes expired keys and returns how many.
+func (s *Store) PurgeIdempotencyKeys(ctx context.Context) (int64, error) {
+	tag, err := s.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= now()`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
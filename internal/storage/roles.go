@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *Store) GetUserRoles(ctx context.Context, userID string) ([]string, error) {
+	rows, err := s.db.Query(ctx,
+		`SELECT role FROM user_roles WHERE user_id=$1 ORDER BY role`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []string{}
+	for rows.Next() {
+		var r string
+		if err := rows.Scan(&r); err != nil {
+			return nil, err
+		}
+		roles = append(roles, r)
+	}
+	return roles, rows.Err()
+}
+
+// SetUserRoles replaces the roles granted to a user.
+func (s *Store) SetUserRoles(ctx context.Context, userID string, roles []string) error {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var exists bool
+	err = tx.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM users WHERE user_id=$1)`,
+		userID,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrUserNotFound
+	}
+
+	var before []string
+	err = tx.QueryRow(ctx,
+		`SELECT COALESCE(array_agg(role ORDER BY role), '{}') FROM user_roles WHERE user_id=$1`,
+		userID,
+	).Scan(&before)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM user_roles WHERE user_id=$1`, userID)
+	if err != nil {
+		return err
+	}
+	for _, r := range roles {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO user_roles(user_id, role) VALUES($1,$2)
+             ON CONFLICT DO NOTHING`,
+			userID, r,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = writeAudit(ctx, tx, "user.set_roles", "user", userID,
+		map[string][]string{"roles": before}, map[string][]string{"roles": roles})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetUserTeams returns the current team of each existing user in userIDs.
+func (s *Store) GetUserTeams(ctx context.Context, userIDs []string) (map[string]string, error) {
+	rows, err := s.db.Query(ctx,
+		`SELECT user_id, team_name FROM users WHERE user_id = ANY($1)`,
+		userIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := map[string]string{}
+	for rows.Next() {
+		var uid, team string
+		if err := rows.Scan(&uid, &team); err != nil {
+			return nil, err
+		}
+		teams[uid] = team
+	}
+	return teams, rows.Err()
+}
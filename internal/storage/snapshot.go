@@ -0,0 +1,393 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+)
+
+// SnapshotVersion is the archive format WriteSnapshot produces and
+// RestoreSnapshot accepts.
+const SnapshotVersion = 1
+
+// What RestoreSnapshot does with archive rows whose key already exists.
+const (
+	ConflictFail      = "fail"
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+)
+
+// SnapshotError rejects a restore before anything is written: references
+// that resolve neither in the archive nor in the database, duplicates, or,
+// in ConflictFail mode, rows that already exist (Conflict).
+type SnapshotError struct {
+	Conflict bool
+	Problems []models.FieldError
+}
+
+func (e *SnapshotError) Error() string {
+	return fmt.Sprintf("snapshot rejected: %d problems, first %s: %s",
+		len(e.Problems), e.Problems[0].Field, e.Problems[0].Reason)
+}
+
+// WriteSnapshot writes a models.Snapshot archive of all teams, users with
+// their roles, pull requests, reviewers and assignment history to w. It
+// reads in one REPEATABLE READ transaction, so the archive is consistent,
+// and streams rows to w instead of loading them first. API keys, the audit
+// log and idempotency records are not included.
+func (s *Store) WriteSnapshot(ctx context.Context, w io.Writer) error {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var now time.Time
+	if err := tx.QueryRow(ctx, `SELECT now()`).Scan(&now); err != nil {
+		return err
+	}
+	createdAt, err := json.Marshal(now)
+	if err != nil {
+		return err
+	}
+	// The object is written by hand so each section can be streamed; the
+	// keys match models.Snapshot.
+	bw := bufio.NewWriterSize(w, 64<<10)
+	fmt.Fprintf(bw, `{"version":%d,"schema_version":%d,"created_at":%s`, SnapshotVersion, SchemaVersion, createdAt)
+
+	var (
+		team models.SnapshotTeam
+		user models.SnapshotUser
+		pr   models.SnapshotPR
+		rev  models.SnapshotReviewer
+		ev   models.SnapshotEvent
+	)
+	sections := []struct {
+		name string
+		sql  string
+		dest []interface{}
+		item interface{}
+	}{
+		{"teams",
+			`SELECT team_name, sla_hours, sla_policy FROM teams ORDER BY team_name`,
+			[]interface{}{&team.TeamName, &team.SLAHours, &team.SLAPolicy}, &team},
+		{"users",
+			`SELECT u.user_id, u.username, u.team_name, u.is_active, COALESCE(u.email, ''), u.daily_digest,
+                    COALESCE(array_agg(r.role ORDER BY r.role) FILTER (WHERE r.role IS NOT NULL), '{}')
+             FROM users u
+             LEFT JOIN user_roles r ON r.user_id = u.user_id
+             GROUP BY u.user_id
+             ORDER BY u.team_name, u.user_id`,
+			[]interface{}{&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.Email, &user.DailyDigest, &user.Roles}, &user},
+		{"pull_requests",
+			`SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at,
+                    last_activity_at, stale_at, closed_at
+             FROM pull_requests ORDER BY created_at, pull_request_id`,
+			[]interface{}{&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt,
+				&pr.LastActivityAt, &pr.StaleAt, &pr.ClosedAt}, &pr},
+		{"reviewers",
+			`SELECT pull_request_id, user_id, assigned_at, sla_escalated_at
+             FROM pr_reviewers ORDER BY pull_request_id, assigned_at, user_id`,
+			[]interface{}{&rev.PullRequestID, &rev.UserID, &rev.AssignedAt, &rev.SLAEscalatedAt}, &rev},
+		{"history",
+			`SELECT pull_request_id, event, user_id, COALESCE(previous_user_id, ''), reason, actor, created_at
+             FROM assignment_history ORDER BY id`,
+			[]interface{}{&ev.PullRequestID, &ev.Event, &ev.UserID, &ev.PreviousUserID, &ev.Reason, &ev.Actor, &ev.CreatedAt}, &ev},
+	}
+	for _, sec := range sections {
+		fmt.Fprintf(bw, `,"%s":[`, sec.name)
+		rows, err := tx.Query(ctx, sec.sql)
+		if err != nil {
+			return err
+		}
+		n := 0
+		err = streamRows(rows, sec.dest, func() error {
+			b, err := json.Marshal(sec.item)
+			if err != nil {
+				return err
+			}
+			if n > 0 {
+				bw.WriteByte(',')
+			}
+			n++
+			_, err = bw.Write(b)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		bw.WriteByte(']')
+	}
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+// RestoreSnapshot loads an archive in a single transaction. Every reference
+// (a user's team, a PR's author, reviewers and history entries) must resolve
+// to a row of the archive or, for teams and users, of the database; this is
+// checked before anything is written and reported as a *SnapshotError.
+// Reviewers and history belong to their pull request: a PR that is skipped
+// keeps its current ones, an overwritten PR gets the archive's.
+func (s *Store) RestoreSnapshot(ctx context.Context, snap models.Snapshot, mode string) (models.RestoreResult, error) {
+	res := models.RestoreResult{Mode: mode}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return res, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var teamKeys, userKeys, prKeys []string
+	for _, t := range snap.Teams {
+		teamKeys = append(teamKeys, t.TeamName)
+	}
+	for _, u := range snap.Users {
+		teamKeys = append(teamKeys, u.TeamName)
+		userKeys = append(userKeys, u.UserID)
+	}
+	for _, p := range snap.PullRequests {
+		userKeys = append(userKeys, p.AuthorID)
+		prKeys = append(prKeys, p.PullRequestID)
+	}
+	for _, r := range snap.Reviewers {
+		userKeys = append(userKeys, r.UserID)
+	}
+	for _, e := range snap.History {
+		userKeys = append(userKeys, e.UserID)
+		if e.PreviousUserID != "" {
+			userKeys = append(userKeys, e.PreviousUserID)
+		}
+	}
+	dbTeams, err := existingKeys(ctx, tx, `SELECT team_name FROM teams WHERE team_name = ANY($1)`, teamKeys)
+	if err != nil {
+		return res, err
+	}
+	dbUsers, err := existingKeys(ctx, tx, `SELECT user_id FROM users WHERE user_id = ANY($1)`, userKeys)
+	if err != nil {
+		return res, err
+	}
+	dbPRs, err := existingKeys(ctx, tx, `SELECT pull_request_id FROM pull_requests WHERE pull_request_id = ANY($1)`, prKeys)
+	if err != nil {
+		return res, err
+	}
+
+	if problems := checkSnapshotRefs(snap, dbTeams, dbUsers); len(problems) > 0 {
+		return res, &SnapshotError{Problems: problems}
+	}
+	if mode == ConflictFail {
+		var conflicts []models.FieldError
+		for i, t := range snap.Teams {
+			if dbTeams[t.TeamName] {
+				conflicts = append(conflicts, models.FieldError{Field: fmt.Sprintf("teams.%d.team_name", i), Reason: "team " + t.TeamName + " already exists"})
+			}
+		}
+		for i, u := range snap.Users {
+			if dbUsers[u.UserID] {
+				conflicts = append(conflicts, models.FieldError{Field: fmt.Sprintf("users.%d.user_id", i), Reason: "user " + u.UserID + " already exists"})
+			}
+		}
+		for i, p := range snap.PullRequests {
+			if dbPRs[p.PullRequestID] {
+				conflicts = append(conflicts, models.FieldError{Field: fmt.Sprintf("pull_requests.%d.pull_request_id", i), Reason: "pull request " + p.PullRequestID + " already exists"})
+			}
+		}
+		if len(conflicts) > 0 {
+			return res, &SnapshotError{Conflict: true, Problems: conflicts}
+		}
+	}
+	overwrite := mode == ConflictOverwrite
+
+	b := &pgx.Batch{}
+	for _, t := range snap.Teams {
+		switch {
+		case !dbTeams[t.TeamName]:
+			b.Queue(`INSERT INTO teams(team_name, sla_hours, sla_policy) VALUES ($1, $2, $3)`,
+				t.TeamName, t.SLAHours, t.SLAPolicy)
+			res.Teams.Created++
+		case overwrite:
+			b.Queue(`UPDATE teams SET sla_hours = $2, sla_policy = $3 WHERE team_name = $1`,
+				t.TeamName, t.SLAHours, t.SLAPolicy)
+			res.Teams.Updated++
+		default:
+			res.Teams.Skipped++
+		}
+	}
+	for _, u := range snap.Users {
+		switch {
+		case !dbUsers[u.UserID]:
+			b.Queue(`INSERT INTO users(user_id, username, team_name, is_active, email, daily_digest)
+                     VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)`,
+				u.UserID, u.Username, u.TeamName, u.IsActive, u.Email, u.DailyDigest)
+			res.Users.Created++
+		case overwrite:
+			b.Queue(`UPDATE users SET username = $2, team_name = $3, is_active = $4, email = NULLIF($5, ''), daily_digest = $6
+                     WHERE user_id = $1`,
+				u.UserID, u.Username, u.TeamName, u.IsActive, u.Email, u.DailyDigest)
+			b.Queue(`DELETE FROM user_roles WHERE user_id = $1`, u.UserID)
+			res.Users.Updated++
+		default:
+			res.Users.Skipped++
+			continue
+		}
+		for _, role := range u.Roles {
+			b.Queue(`INSERT INTO user_roles(user_id, role) VALUES ($1, $2)`, u.UserID, role)
+		}
+	}
+
+	restored := make(map[string]bool, len(snap.PullRequests))
+	for _, p := range snap.PullRequests {
+		last := p.LastActivityAt
+		if last.IsZero() {
+			last = p.CreatedAt
+		}
+		switch {
+		case !dbPRs[p.PullRequestID]:
+			b.Queue(`INSERT INTO pull_requests(pull_request_id, pull_request_name, author_id, status, created_at,
+                         merged_at, last_activity_at, stale_at, closed_at)
+                     VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+				p.PullRequestID, p.PullRequestName, p.AuthorID, p.Status, p.CreatedAt, p.MergedAt, last, p.StaleAt, p.ClosedAt)
+			res.PullRequests.Created++
+		case overwrite:
+			b.Queue(`UPDATE pull_requests SET pull_request_name = $2, author_id = $3, status = $4, created_at = $5,
+                         merged_at = $6, last_activity_at = $7, stale_at = $8, closed_at = $9
+                     WHERE pull_request_id = $1`,
+				p.PullRequestID, p.PullRequestName, p.AuthorID, p.Status, p.CreatedAt, p.MergedAt, last, p.StaleAt, p.ClosedAt)
+			b.Queue(`DELETE FROM pr_reviewers WHERE pull_request_id = $1`, p.PullRequestID)
+			b.Queue(`DELETE FROM assignment_history WHERE pull_request_id = $1`, p.PullRequestID)
+			res.PullRequests.Updated++
+		default:
+			res.PullRequests.Skipped++
+			continue
+		}
+		restored[p.PullRequestID] = true
+	}
+	for _, r := range snap.Reviewers {
+		if !restored[r.PullRequestID] {
+			res.Reviewers.Skipped++
+			continue
+		}
+		b.Queue(`INSERT INTO pr_reviewers(pull_request_id, user_id, assigned_at, sla_escalated_at) VALUES ($1, $2, $3, $4)`,
+			r.PullRequestID, r.UserID, r.AssignedAt, r.SLAEscalatedAt)
+		res.Reviewers.Created++
+	}
+	for _, e := range snap.History {
+		if !restored[e.PullRequestID] {
+			res.History.Skipped++
+			continue
+		}
+		var prev *string
+		if e.PreviousUserID != "" {
+			prev = &e.PreviousUserID
+		}
+		b.Queue(`INSERT INTO assignment_history(pull_request_id, event, user_id, previous_user_id, reason, actor, created_at)
+                 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			e.PullRequestID, e.Event, e.UserID, prev, e.Reason, e.Actor, e.CreatedAt)
+		res.History.Created++
+	}
+
+	if err := tx.SendBatch(ctx, b).Close(); err != nil {
+		return res, err
+	}
+	if err := writeAudit(ctx, tx, "snapshot.restore", "snapshot", snap.CreatedAt.UTC().Format(time.RFC3339), nil, res); err != nil {
+		return res, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// checkSnapshotRefs lists duplicate keys of the archive and references that
+// resolve neither in it nor, for teams and users, in the database.
+func checkSnapshotRefs(snap models.Snapshot, dbTeams, dbUsers map[string]bool) []models.FieldError {
+	var problems []models.FieldError
+	add := func(field string, i int, reason string) {
+		problems = append(problems, models.FieldError{Field: fmt.Sprintf(field, i), Reason: reason})
+	}
+
+	teams := make(map[string]bool, len(snap.Teams))
+	for i, t := range snap.Teams {
+		if teams[t.TeamName] {
+			add("teams.%d.team_name", i, "duplicate team "+t.TeamName)
+		}
+		teams[t.TeamName] = true
+	}
+	users := make(map[string]bool, len(snap.Users))
+	for i, u := range snap.Users {
+		if users[u.UserID] {
+			add("users.%d.user_id", i, "duplicate user "+u.UserID)
+		}
+		users[u.UserID] = true
+		if !teams[u.TeamName] && !dbTeams[u.TeamName] {
+			add("users.%d.team_name", i, "team "+u.TeamName+" is neither in the archive nor in the database")
+		}
+	}
+	knownUser := func(id string) bool { return users[id] || dbUsers[id] }
+
+	prs := make(map[string]bool, len(snap.PullRequests))
+	for i, p := range snap.PullRequests {
+		if prs[p.PullRequestID] {
+			add("pull_requests.%d.pull_request_id", i, "duplicate pull request "+p.PullRequestID)
+		}
+		prs[p.PullRequestID] = true
+		if !knownUser(p.AuthorID) {
+			add("pull_requests.%d.author_id", i, "user "+p.AuthorID+" is neither in the archive nor in the database")
+		}
+	}
+	reviewers := make(map[[2]string]bool, len(snap.Reviewers))
+	for i, r := range snap.Reviewers {
+		key := [2]string{r.PullRequestID, r.UserID}
+		if reviewers[key] {
+			add("reviewers.%d", i, "duplicate reviewer "+r.UserID+" of "+r.PullRequestID)
+		}
+		reviewers[key] = true
+		if !prs[r.PullRequestID] {
+			add("reviewers.%d.pull_request_id", i, "pull request "+r.PullRequestID+" is not in the archive")
+		}
+		if !knownUser(r.UserID) {
+			add("reviewers.%d.user_id", i, "user "+r.UserID+" is neither in the archive nor in the database")
+		}
+	}
+	for i, e := range snap.History {
+		if !prs[e.PullRequestID] {
+			add("history.%d.pull_request_id", i, "pull request "+e.PullRequestID+" is not in the archive")
+		}
+		if !knownUser(e.UserID) {
+			add("history.%d.user_id", i, "user "+e.UserID+" is neither in the archive nor in the database")
+		}
+		if e.PreviousUserID != "" && !knownUser(e.PreviousUserID) {
+			add("history.%d.previous_user_id", i, "user "+e.PreviousUserID+" is neither in the archive nor in the database")
+		}
+	}
+	return problems
+}
+
+func existingKeys(ctx context.Context, q querier, sql string, keys []string) (map[string]bool, error) {
+	found := make(map[string]bool)
+	if len(keys) == 0 {
+		return found, nil
+	}
+	rows, err := q.Query(ctx, sql, keys)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		found[id] = true
+	}
+	return found, nil
+}
@@ -0,0 +1,203 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"Backend-trainee-assignment-autumn-2025/internal/models"
+)
+
+// StatsFilter narrows GetStats. TeamName is the reviewer's team for
+// assignments and the author's team for pull requests. From (inclusive) and
+// To (exclusive) bound when assignments were made and when pull requests
+// were created; open assignments are the current load and ignore them.
+type StatsFilter struct {
+	TeamName string
+	From     *time.Time
+	To       *time.Time
+}
+
+// GetStats computes assignment counts, per-user load, time-to-merge and
+// time-to-first-review, overall and per team. Everything is aggregated by
+// the database, in one REPEATABLE READ transaction so the parts agree.
+//
+// The service records no review activity, so time-to-first-review is the
+// time from a PR's creation to the first reviewer assignment in its
+// history; it is zero for PRs that got reviewers when created.
+func (s *Store) GetStats(ctx context.Context, f StatsFilter) (models.Stats, error) {
+	st := models.Stats{
+		ReviewerAssignments: map[string]int{},
+		PRAssignments:       map[string]int{},
+		Users:               []models.UserStats{},
+		Teams:               []models.TeamStats{},
+	}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return st, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var key string
+	var n int
+	rows, err := tx.Query(ctx,
+		`SELECT r.user_id, COUNT(*)
+         FROM pr_reviewers r
+         JOIN users u ON u.user_id = r.user_id
+         WHERE ($1 = '' OR u.team_name = $1)
+           AND ($2::timestamptz IS NULL OR r.assigned_at >= $2)
+           AND ($3::timestamptz IS NULL OR r.assigned_at < $3)
+         GROUP BY r.user_id`,
+		f.TeamName, f.From, f.To)
+	if err != nil {
+		return st, err
+	}
+	if err := streamRows(rows, []interface{}{&key, &n}, func() error { st.ReviewerAssignments[key] = n; return nil }); err != nil {
+		return st, err
+	}
+
+	rows, err = tx.Query(ctx,
+		`SELECT r.pull_request_id, COUNT(*)
+         FROM pr_reviewers r
+         JOIN pull_requests p ON p.pull_request_id = r.pull_request_id
+         JOIN users a ON a.user_id = p.author_id
+         WHERE ($1 = '' OR a.team_name = $1)
+           AND ($2::timestamptz IS NULL OR p.created_at >= $2)
+           AND ($3::timestamptz IS NULL OR p.created_at < $3)
+         GROUP BY r.pull_request_id`,
+		f.TeamName, f.From, f.To)
+	if err != nil {
+		return st, err
+	}
+	if err := streamRows(rows, []interface{}{&key, &n}, func() error { st.PRAssignments[key] = n; return nil }); err != nil {
+		return st, err
+	}
+
+	teams := map[string]*models.TeamStats{}
+	team := func(name string) *models.TeamStats {
+		t, ok := teams[name]
+		if !ok {
+			t = &models.TeamStats{TeamName: name}
+			teams[name] = t
+		}
+		return t
+	}
+
+	// One row per user who is active or has assignments, plus one total
+	// row per team (teamRow).
+	var (
+		teamRow bool
+		u       models.UserStats
+		active  int
+	)
+	rows, err = tx.Query(ctx,
+		`WITH total AS (
+             SELECT user_id, COUNT(*) AS n
+             FROM assignment_history
+             WHERE event IN ('assigned', 'replaced')
+               AND ($2::timestamptz IS NULL OR created_at >= $2)
+               AND ($3::timestamptz IS NULL OR created_at < $3)
+             GROUP BY user_id
+         ), open AS (
+             SELECT r.user_id, COUNT(*) AS n
+             FROM pr_reviewers r
+             JOIN pull_requests p ON p.pull_request_id = r.pull_request_id
+             WHERE p.status = 'OPEN'
+             GROUP BY r.user_id
+         )
+         SELECT GROUPING(u.user_id) = 1, u.team_name, COALESCE(u.user_id, ''), COALESCE(u.username, ''),
+                bool_or(u.is_active), COUNT(*) FILTER (WHERE u.is_active),
+                COALESCE(SUM(o.n), 0)::int, COALESCE(SUM(t.n), 0)::int
+         FROM users u
+         LEFT JOIN total t ON t.user_id = u.user_id
+         LEFT JOIN open o ON o.user_id = u.user_id
+         WHERE ($1 = '' OR u.team_name = $1)
+           AND (u.is_active OR t.n IS NOT NULL OR o.n IS NOT NULL)
+         GROUP BY GROUPING SETS ((u.team_name, u.user_id, u.username), (u.team_name))
+         ORDER BY u.team_name, GROUPING(u.user_id) DESC, u.user_id`,
+		f.TeamName, f.From, f.To)
+	if err != nil {
+		return st, err
+	}
+	err = streamRows(rows, []interface{}{&teamRow, &u.TeamName, &u.UserID, &u.Username,
+		&u.IsActive, &active, &u.OpenAssignments, &u.TotalAssignments},
+		func() error {
+			if teamRow {
+				t := team(u.TeamName)
+				t.ActiveMembers, t.OpenAssignments, t.TotalAssignments = active, u.OpenAssignments, u.TotalAssignments
+			} else {
+				st.Users = append(st.Users, u)
+			}
+			return nil
+		})
+	if err != nil {
+		return st, err
+	}
+
+	// Durations per author team and, in the row without a team, overall.
+	var (
+		overall      bool
+		teamName     *string
+		prs          int
+		merge, first models.DurationStats
+	)
+	rows, err = tx.Query(ctx,
+		`WITH prs AS (
+             SELECT a.team_name,
+                    EXTRACT(EPOCH FROM p.merged_at - p.created_at)::float8 AS to_merge,
+                    EXTRACT(EPOCH FROM (
+                        SELECT MIN(h.created_at)
+                        FROM assignment_history h
+                        WHERE h.pull_request_id = p.pull_request_id
+                          AND h.event IN ('assigned', 'replaced')
+                    ) - p.created_at)::float8 AS to_first_review
+             FROM pull_requests p
+             JOIN users a ON a.user_id = p.author_id
+             WHERE ($1 = '' OR a.team_name = $1)
+               AND ($2::timestamptz IS NULL OR p.created_at >= $2)
+               AND ($3::timestamptz IS NULL OR p.created_at < $3)
+         )
+         SELECT GROUPING(team_name) = 1, team_name, COUNT(*),
+                COUNT(to_merge),
+                percentile_cont(0.5) WITHIN GROUP (ORDER BY to_merge),
+                percentile_cont(0.9) WITHIN GROUP (ORDER BY to_merge),
+                COUNT(to_first_review),
+                percentile_cont(0.5) WITHIN GROUP (ORDER BY to_first_review),
+                percentile_cont(0.9) WITHIN GROUP (ORDER BY to_first_review)
+         FROM prs
+         GROUP BY GROUPING SETS ((team_name), ())`,
+		f.TeamName, f.From, f.To)
+	if err != nil {
+		return st, err
+	}
+	err = streamRows(rows, []interface{}{&overall, &teamName, &prs,
+		&merge.Count, &merge.MedianSeconds, &merge.P90Seconds,
+		&first.Count, &first.MedianSeconds, &first.P90Seconds},
+		func() error {
+			if overall {
+				st.TimeToMerge, st.TimeToFirstReview = merge, first
+			} else {
+				t := team(*teamName)
+				t.PullRequests, t.Merged = prs, merge.Count
+				t.TimeToMerge, t.TimeToFirstReview = merge, first
+			}
+			// The percentiles were copied by pointer; the next row must
+			// scan into new ones.
+			merge, first = models.DurationStats{}, models.DurationStats{}
+			return nil
+		})
+	if err != nil {
+		return st, err
+	}
+
+	for _, t := range teams {
+		st.Teams = append(st.Teams, *t)
+	}
+	sort.Slice(st.Teams, func(i, j int) bool { return st.Teams[i].TeamName < st.Teams[j].TeamName })
+	return st, tx.Commit(ctx)
+}
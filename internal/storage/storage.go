@@ -9,15 +9,39 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"Backend-trainee-assignment-autumn-2025/internal/metrics"
 	"Backend-trainee-assignment-autumn-2025/internal/models"
 )
 
+// PRFilter narrows pull request listings. Zero values match everything.
+type PRFilter struct {
+	Status   string
+	Stale    *bool
+	AuthorID string
+	TeamName string
+}
+
 type Store struct {
-	db *pgxpool.Pool
+	db             *pgxpool.Pool
+	reviewersPerPR int
+}
+
+type StoreOption func(*Store)
+
+// WithReviewersPerPR sets how many reviewers CreatePR assigns. The default
+// is 2.
+func WithReviewersPerPR(n int) StoreOption {
+	return func(s *Store) {
+		s.reviewersPerPR = n
+	}
 }
 
-func NewStore(db *pgxpool.Pool) *Store {
-	return &Store{db: db}
+func NewStore(db *pgxpool.Pool, opts ...StoreOption) *Store {
+	s := &Store{db: db, reviewersPerPR: 2}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 var (
@@ -26,6 +50,7 @@ var (
 	ErrPRExists     = errors.New("PR_EXISTS")
 	ErrPRNotFound   = errors.New("pr not found")
 	ErrPRMerged     = errors.New("PR_MERGED")
+	ErrPRClosed     = errors.New("PR_CLOSED")
 	ErrNotAssigned  = errors.New("NOT_ASSIGNED")
 	ErrNoCandidate  = errors.New("NO_CANDIDATE")
 )
@@ -39,6 +64,16 @@ func (s *Store) UpsertTeam(ctx context.Context, t models.Team) error {
 		_ = tx.Rollback(ctx)
 	}()
 
+	var before interface{}
+	prev, err := getTeam(ctx, tx, t.TeamName)
+	switch err {
+	case nil:
+		before = prev
+	case ErrTeamNotFound:
+	default:
+		return err
+	}
+
 	_, err = tx.Exec(ctx,
 		`INSERT INTO teams(team_name) VALUES($1)
 		 ON CONFLICT (team_name) DO NOTHING`,
@@ -50,27 +85,41 @@ func (s *Store) UpsertTeam(ctx context.Context, t models.Team) error {
 
 	for _, m := range t.Members {
 		_, err := tx.Exec(ctx,
-			`INSERT INTO users(user_id, username, team_name, is_active)
-             VALUES($1,$2,$3,$4)
+			`INSERT INTO users(user_id, username, team_name, is_active, email, daily_digest)
+             VALUES($1,$2,$3,$4,NULLIF($5,''),$6)
 			 ON CONFLICT (user_id)
 			 DO UPDATE SET username=EXCLUDED.username,
 			               team_name=EXCLUDED.team_name,
-			               is_active=EXCLUDED.is_active`,
-			m.UserID, m.Username, t.TeamName, m.IsActive,
+			               is_active=EXCLUDED.is_active,
+			               email=EXCLUDED.email,
+			               daily_digest=EXCLUDED.daily_digest`,
+			m.UserID, m.Username, t.TeamName, m.IsActive, m.Email, m.DailyDigest,
 		)
 		if err != nil {
 			return err
 		}
 	}
 
+	after, err := getTeam(ctx, tx, t.TeamName)
+	if err != nil {
+		return err
+	}
+	if err := writeAudit(ctx, tx, "team.upsert", "team", t.TeamName, before, after); err != nil {
+		return err
+	}
+
 	return tx.Commit(ctx)
 }
 
 func (s *Store) GetTeam(ctx context.Context, teamName string) (models.Team, error) {
+	return getTeam(ctx, s.db, teamName)
+}
+
+func getTeam(ctx context.Context, q querier, teamName string) (models.Team, error) {
 	var t models.Team
 
-	rows, err := s.db.Query(ctx,
-		`SELECT user_id, username, is_active
+	rows, err := q.Query(ctx,
+		`SELECT user_id, username, is_active, COALESCE(email, ''), daily_digest
          FROM users
          WHERE team_name=$1`,
 		teamName,
@@ -83,15 +132,16 @@ func (s *Store) GetTeam(ctx context.Context, teamName string) (models.Team, erro
 	members := []models.TeamMember{}
 	for rows.Next() {
 		var m models.TeamMember
-		if err := rows.Scan(&m.UserID, &m.Username, &m.IsActive); err != nil {
+		if err := rows.Scan(&m.UserID, &m.Username, &m.IsActive, &m.Email, &m.DailyDigest); err != nil {
 			return t, err
 		}
 		members = append(members, m)
 	}
+	rows.Close()
 
 	if len(members) == 0 {
 		var exists bool
-		err = s.db.QueryRow(ctx,
+		err = q.QueryRow(ctx,
 			`SELECT EXISTS(SELECT 1 FROM teams WHERE team_name=$1)`,
 			teamName,
 		).Scan(&exists)
@@ -109,35 +159,54 @@ func (s *Store) GetTeam(ctx context.Context, teamName string) (models.Team, erro
 }
 
 func (s *Store) SetUserActive(ctx context.Context, userID string, isActive bool) (models.User, error) {
-	var u models.User
-
-	cmd, err := s.db.Exec(ctx,
-		`UPDATE users SET is_active=$1 WHERE user_id=$2`,
-		isActive, userID,
+	return s.updateUser(ctx, userID, "user.set_active",
+		`UPDATE users SET is_active=$2 WHERE user_id=$1`,
+		isActive,
 	)
+}
+
+// updateUser runs a single-row UPDATE on users keyed by $1 and records the
+// before/after snapshots in the audit log.
+func (s *Store) updateUser(ctx context.Context, userID, action, sql string, args ...interface{}) (models.User, error) {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
-		return u, err
+		return models.User{}, err
 	}
-	if cmd.RowsAffected() == 0 {
-		return u, ErrUserNotFound
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	before, err := getUser(ctx, tx, userID)
+	if err != nil {
+		return models.User{}, err
 	}
 
-	err = s.db.QueryRow(ctx,
-		`SELECT user_id, username, team_name, is_active
-         FROM users WHERE user_id=$1`,
-		userID,
-	).Scan(&u.UserID, &u.Username, &u.TeamName, &u.IsActive)
+	if _, err := tx.Exec(ctx, sql, append([]interface{}{userID}, args...)...); err != nil {
+		return models.User{}, err
+	}
 
-	return u, err
+	after, err := getUser(ctx, tx, userID)
+	if err != nil {
+		return models.User{}, err
+	}
+	if err := writeAudit(ctx, tx, action, "user", userID, before, after); err != nil {
+		return models.User{}, err
+	}
+
+	return after, tx.Commit(ctx)
 }
 
 func (s *Store) GetUser(ctx context.Context, userID string) (models.User, error) {
+	return getUser(ctx, s.db, userID)
+}
+
+func getUser(ctx context.Context, q querier, userID string) (models.User, error) {
 	var u models.User
-	err := s.db.QueryRow(ctx,
-		`SELECT user_id, username, team_name, is_active
+	err := q.QueryRow(ctx,
+		`SELECT user_id, username, team_name, is_active, COALESCE(email, ''), daily_digest
          FROM users WHERE user_id=$1`,
 		userID,
-	).Scan(&u.UserID, &u.Username, &u.TeamName, &u.IsActive)
+	).Scan(&u.UserID, &u.Username, &u.TeamName, &u.IsActive, &u.Email, &u.DailyDigest)
 
 	if errors.Is(err, pgx.ErrNoRows) {
 		return u, ErrUserNotFound
@@ -145,6 +214,39 @@ func (s *Store) GetUser(ctx context.Context, userID string) (models.User, error)
 	return u, err
 }
 
+func (s *Store) SetUserNotifications(ctx context.Context, userID, email string, dailyDigest bool) (models.User, error) {
+	return s.updateUser(ctx, userID, "user.set_notifications",
+		`UPDATE users SET email=NULLIF($2,''), daily_digest=$3 WHERE user_id=$1`,
+		email, dailyDigest,
+	)
+}
+
+// GetDigestSubscribers returns active users that opted in to the daily
+// review digest and have an email address.
+func (s *Store) GetDigestSubscribers(ctx context.Context) ([]models.User, error) {
+	rows, err := s.db.Query(ctx,
+		`SELECT user_id, username, team_name, is_active, email, daily_digest
+         FROM users
+         WHERE daily_digest=true
+           AND is_active=true
+           AND email IS NOT NULL`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []models.User
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.UserID, &u.Username, &u.TeamName, &u.IsActive, &u.Email, &u.DailyDigest); err != nil {
+			return nil, err
+		}
+		res = append(res, u)
+	}
+	return res, rows.Err()
+}
+
 func (s *Store) CreatePR(ctx context.Context, pr models.PullRequest) (models.PullRequest, error) {
 	var exists bool
 	err := s.db.QueryRow(ctx,
@@ -199,8 +301,8 @@ func (s *Store) CreatePR(ctx context.Context, pr models.PullRequest) (models.Pul
            AND is_active=true
            AND user_id <> $2
          ORDER BY random()
-         LIMIT 2`,
-		team, pr.AuthorID,
+         LIMIT $3`,
+		team, pr.AuthorID, s.reviewersPerPR,
 	)
 	if err != nil {
 		return pr, err
@@ -231,25 +333,43 @@ func (s *Store) CreatePR(ctx context.Context, pr models.PullRequest) (models.Pul
 		if err != nil {
 			return pr, err
 		}
+		if err := recordAssignment(ctx, tx, pr.PullRequestID, EventAssigned, uid, "", ReasonInitial); err != nil {
+			return pr, err
+		}
+	}
+
+	created, err := getPR(ctx, tx, pr.PullRequestID)
+	if err != nil {
+		return pr, err
+	}
+	if err := writeAudit(ctx, tx, "pr.create", "pull_request", pr.PullRequestID, nil, created); err != nil {
+		return pr, err
 	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return pr, err
 	}
+	metrics.PRCreated()
 
-	return s.GetPR(ctx, pr.PullRequestID)
+	return created, nil
 }
 
 func (s *Store) GetPR(ctx context.Context, prID string) (models.PullRequest, error) {
+	return getPR(ctx, s.db, prID)
+}
+
+func getPR(ctx context.Context, q querier, prID string) (models.PullRequest, error) {
 	var p models.PullRequest
 	var createdAt time.Time
 	var mergedAt *time.Time
 
-	err := s.db.QueryRow(ctx,
-		`SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at
+	err := q.QueryRow(ctx,
+		`SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at,
+                stale_at, closed_at
          FROM pull_requests WHERE pull_request_id=$1`,
 		prID,
-	).Scan(&p.PullRequestID, &p.PullRequestName, &p.AuthorID, &p.Status, &createdAt, &mergedAt)
+	).Scan(&p.PullRequestID, &p.PullRequestName, &p.AuthorID, &p.Status, &createdAt, &mergedAt,
+		&p.StaleSince, &p.ClosedAt)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return p, ErrPRNotFound
 	}
@@ -261,9 +381,10 @@ func (s *Store) GetPR(ctx context.Context, prID string) (models.PullRequest, err
 	if mergedAt != nil {
 		p.MergedAt = mergedAt
 	}
+	p.IsStale = p.StaleSince != nil
 
-	rows, err := s.db.Query(ctx,
-		`SELECT user_id FROM pr_reviewers WHERE pull_request_id=$1`,
+	rows, err := q.Query(ctx,
+		`SELECT user_id FROM pr_reviewers WHERE pull_request_id=$1 ORDER BY assigned_at, user_id`,
 		prID,
 	)
 	if err != nil {
@@ -271,6 +392,7 @@ func (s *Store) GetPR(ctx context.Context, prID string) (models.PullRequest, err
 	}
 	defer rows.Close()
 
+	p.AssignedReviewers = []string{}
 	for rows.Next() {
 		var uid string
 		if err := rows.Scan(&uid); err != nil {
@@ -279,7 +401,7 @@ func (s *Store) GetPR(ctx context.Context, prID string) (models.PullRequest, err
 		p.AssignedReviewers = append(p.AssignedReviewers, uid)
 	}
 
-	return p, nil
+	return p, rows.Err()
 }
 
 func (s *Store) MergePR(ctx context.Context, prID string) (models.PullRequest, error) {
@@ -311,6 +433,14 @@ func (s *Store) MergePR(ctx context.Context, prID string) (models.PullRequest, e
 		_ = tx.Commit(ctx)
 		return s.GetPR(ctx, prID)
 	}
+	if status == "CLOSED" {
+		return models.PullRequest{}, ErrPRClosed
+	}
+
+	before, err := getPR(ctx, tx, prID)
+	if err != nil {
+		return models.PullRequest{}, err
+	}
 
 	now := time.Now().UTC()
 
@@ -324,14 +454,26 @@ func (s *Store) MergePR(ctx context.Context, prID string) (models.PullRequest, e
 		return models.PullRequest{}, err
 	}
 
+	after, err := getPR(ctx, tx, prID)
+	if err != nil {
+		return models.PullRequest{}, err
+	}
+	if err := writeAudit(ctx, tx, "pr.merge", "pull_request", prID, before, after); err != nil {
+		return models.PullRequest{}, err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return models.PullRequest{}, err
 	}
+	metrics.PRMerged()
 
-	return s.GetPR(ctx, prID)
+	return after, nil
 }
 
-func (s *Store) ReassignReviewer(ctx context.Context, prID, oldUserID string) (models.PullRequest, string, error) {
+// ReassignReviewer replaces oldUserID with a random active member of that
+// reviewer's team. reason is recorded in the assignment history. With dryRun
+// the change is rolled back and the PR is returned as it would have been.
+func (s *Store) ReassignReviewer(ctx context.Context, prID, oldUserID, reason string, dryRun bool) (models.PullRequest, string, error) {
 	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return models.PullRequest{}, "", err
@@ -340,8 +482,32 @@ func (s *Store) ReassignReviewer(ctx context.Context, prID, oldUserID string) (m
 		_ = tx.Rollback(ctx)
 	}()
 
+	after, newReviewer, err := reassign(ctx, tx, prID, oldUserID, reason, func(candidates []string) string {
+		return candidates[rand.Intn(len(candidates))]
+	})
+	if err == ErrNoCandidate && !dryRun {
+		metrics.NoCandidate("reassign")
+	}
+	if err != nil || dryRun {
+		return after, newReviewer, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.PullRequest{}, "", err
+	}
+	metrics.Reassigned(reason)
+
+	return after, newReviewer, nil
+}
+
+// reassign applies the ReassignReviewer rules inside tx: the PR must be open
+// and have oldUserID as a reviewer, and the replacement is chosen by pick
+// among the active members of oldUserID's team who are neither the author
+// nor already reviewing. pick returning "" means none of them will do and
+// fails with ErrNoCandidate, as does an empty candidate list.
+func reassign(ctx context.Context, tx pgx.Tx, prID, oldUserID, reason string, pick func([]string) string) (models.PullRequest, string, error) {
 	var status string
-	err = tx.QueryRow(ctx,
+	err := tx.QueryRow(ctx,
 		`SELECT status FROM pull_requests
          WHERE pull_request_id=$1 FOR UPDATE`,
 		prID,
@@ -355,6 +521,14 @@ func (s *Store) ReassignReviewer(ctx context.Context, prID, oldUserID string) (m
 	if status == "MERGED" {
 		return models.PullRequest{}, "", ErrPRMerged
 	}
+	if status == "CLOSED" {
+		return models.PullRequest{}, "", ErrPRClosed
+	}
+
+	before, err := getPR(ctx, tx, prID)
+	if err != nil {
+		return models.PullRequest{}, "", err
+	}
 
 	var assigned bool
 	err = tx.QueryRow(ctx,
@@ -439,8 +613,10 @@ func (s *Store) ReassignReviewer(ctx context.Context, prID, oldUserID string) (m
 	if len(candidates) == 0 {
 		return models.PullRequest{}, "", ErrNoCandidate
 	}
-
-	newReviewer := candidates[rand.Intn(len(candidates))]
+	newReviewer := pick(candidates)
+	if newReviewer == "" {
+		return models.PullRequest{}, "", ErrNoCandidate
+	}
 
 	_, err = tx.Exec(ctx,
 		`DELETE FROM pr_reviewers
@@ -459,198 +635,467 @@ func (s *Store) ReassignReviewer(ctx context.Context, prID, oldUserID string) (m
 	if err != nil {
 		return models.PullRequest{}, "", err
 	}
+	if err := recordAssignment(ctx, tx, prID, EventReplaced, newReviewer, oldUserID, reason); err != nil {
+		return models.PullRequest{}, "", err
+	}
 
-	if err := tx.Commit(ctx); err != nil {
+	if err := touchPR(ctx, tx, prID); err != nil {
 		return models.PullRequest{}, "", err
 	}
 
-	pr, err := s.GetPR(ctx, prID)
-	return pr, newReviewer, err
+	after, err := getPR(ctx, tx, prID)
+	if err != nil {
+		return models.PullRequest{}, "", err
+	}
+	if err := writeAudit(ctx, tx, "pr.reassign", "pull_request", prID, before, after); err != nil {
+		return models.PullRequest{}, "", err
+	}
+	return after, newReviewer, nil
 }
 
-func (s *Store) GetPRsForReviewer(ctx context.Context, userID string) ([]models.PullRequestShort, error) {
+func (s *Store) GetPRsForReviewer(ctx context.Context, userID string, f PRFilter) ([]models.PullRequestShort, error) {
 	rows, err := s.db.Query(ctx,
-		`SELECT p.pull_request_id, p.pull_request_name, p.author_id, p.status
+		`SELECT p.pull_request_id, p.pull_request_name, p.author_id, p.status, p.stale_at IS NOT NULL
          FROM pull_requests p
          JOIN pr_reviewers r ON p.pull_request_id = r.pull_request_id
-         WHERE r.user_id = $1`,
-		userID,
+         WHERE r.user_id = $1
+           AND ($2 = '' OR p.status = $2)
+           AND ($3::boolean IS NULL OR (p.stale_at IS NOT NULL) = $3)`,
+		userID, f.Status, f.Stale,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var res []models.PullRequestShort
+	return scanPRShorts(rows)
+}
+
+func (s *Store) ListPRs(ctx context.Context, f PRFilter) ([]models.PullRequestShort, error) {
+	rows, err := s.db.Query(ctx,
+		`SELECT p.pull_request_id, p.pull_request_name, p.author_id, p.status, p.stale_at IS NOT NULL
+         FROM pull_requests p
+         JOIN users a ON a.user_id = p.author_id
+         WHERE ($1 = '' OR p.status = $1)
+           AND ($2::boolean IS NULL OR (p.stale_at IS NOT NULL) = $2)
+           AND ($3 = '' OR p.author_id = $3)
+           AND ($4 = '' OR a.team_name = $4)
+         ORDER BY p.created_at, p.pull_request_id`,
+		f.Status, f.Stale, f.AuthorID, f.TeamName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanPRShorts(rows)
+}
+
+func scanPRShorts(rows pgx.Rows) ([]models.PullRequestShort, error) {
+	res := []models.PullRequestShort{}
 	for rows.Next() {
 		var p models.PullRequestShort
-		if err := rows.Scan(&p.PullRequestID, &p.PullRequestName, &p.AuthorID, &p.Status); err != nil {
+		if err := rows.Scan(&p.PullRequestID, &p.PullRequestName, &p.AuthorID, &p.Status, &p.IsStale); err != nil {
 			return nil, err
 		}
 		res = append(res, p)
 	}
-	return res, nil
+	return res, rows.Err()
 }
 
-func (s *Store) GetReviewerStats(ctx context.Context) (map[string]int, error) {
-	rows, err := s.db.Query(ctx,
-		`SELECT user_id, COUNT(*)
-         FROM pr_reviewers
-         GROUP BY user_id`)
+// touchPR records activity on an open pull request and clears its stale flag.
+func touchPR(ctx context.Context, tx pgx.Tx, prID string) error {
+	_, err := tx.Exec(ctx,
+		`UPDATE pull_requests
+         SET last_activity_at=now(), stale_at=NULL
+         WHERE pull_request_id=$1`,
+		prID,
+	)
+	return err
+}
+
+// MarkStalePRs flags OPEN pull requests with no activity since the given
+// time and returns the newly flagged ones.
+func (s *Store) MarkStalePRs(ctx context.Context, inactiveSince time.Time) ([]models.PullRequestShort, error) {
+	return s.updatePRsAudited(ctx, "pr.mark_stale",
+		map[string]bool{"is_stale": false}, map[string]bool{"is_stale": true},
+		`UPDATE pull_requests
+         SET stale_at=now()
+         WHERE status='OPEN'
+           AND stale_at IS NULL
+           AND last_activity_at < $1
+         RETURNING pull_request_id, pull_request_name, author_id, status, true`,
+		inactiveSince,
+	)
+}
+
+// CloseStalePRs moves pull requests flagged stale before the given time to
+// CLOSED and returns them.
+func (s *Store) CloseStalePRs(ctx context.Context, staleBefore time.Time) ([]models.PullRequestShort, error) {
+	return s.updatePRsAudited(ctx, "pr.close_stale",
+		map[string]string{"status": "OPEN"}, map[string]string{"status": "CLOSED"},
+		`UPDATE pull_requests
+         SET status='CLOSED', closed_at=now()
+         WHERE status='OPEN'
+           AND stale_at < $1
+         RETURNING pull_request_id, pull_request_name, author_id, status, true`,
+		staleBefore,
+	)
+}
+
+// updatePRsAudited runs a bulk UPDATE ... RETURNING on pull_requests and
+// writes one audit entry with the given before/after for every returned row.
+func (s *Store) updatePRsAudited(ctx context.Context, action string, before, after interface{}, sql string, args ...interface{}) ([]models.PullRequestShort, error) {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
 
-	stats := map[string]int{}
-	for rows.Next() {
-		var uid string
-		var cnt int
-		if err := rows.Scan(&uid, &cnt); err != nil {
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	prs, err := scanPRShorts(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pr := range prs {
+		if err := writeAudit(ctx, tx, action, "pull_request", pr.PullRequestID, before, after); err != nil {
 			return nil, err
 		}
-		stats[uid] = cnt
 	}
-	return stats, nil
+
+	return prs, tx.Commit(ctx)
 }
 
-func (s *Store) GetPRStats(ctx context.Context) (map[string]int, error) {
+// OpenReviewsByTeam counts reviewer assignments on OPEN pull requests,
+// grouped by the reviewer's team.
+func (s *Store) OpenReviewsByTeam(ctx context.Context) (map[string]int, error) {
 	rows, err := s.db.Query(ctx,
-		`SELECT pull_request_id, COUNT(*)
-         FROM pr_reviewers
-         GROUP BY pull_request_id`)
+		`SELECT u.team_name, COUNT(*)
+         FROM pr_reviewers r
+         JOIN pull_requests p ON p.pull_request_id = r.pull_request_id
+         JOIN users u ON u.user_id = r.user_id
+         WHERE p.status='OPEN'
+         GROUP BY u.team_name`,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	stats := map[string]int{}
+	res := map[string]int{}
 	for rows.Next() {
-		var prID string
-		var cnt int
-		if err := rows.Scan(&prID, &cnt); err != nil {
+		var team string
+		var n int
+		if err := rows.Scan(&team, &n); err != nil {
 			return nil, err
 		}
-		stats[prID] = cnt
+		res[team] = n
 	}
-	return stats, nil
+	return res, rows.Err()
 }
 
-func (s *Store) BulkDeactivateUsers(ctx context.Context, teamName string, userIDs []string) error {
+// BulkDeactivateUsers deactivates the active users of teamName among
+// userIDs, removes them from the open pull requests they review and gives
+// each one left without a reviewer a random active team member. IDs that
+// do not exist, belong to another team or are already inactive are
+// reported and otherwise ignored. With dryRun the transaction is rolled
+// back, so the result only tells what would have changed; the random picks
+// may differ on a real run.
+func (s *Store) BulkDeactivateUsers(ctx context.Context, teamName string, userIDs []string, dryRun bool) (Deactivation, error) {
+	res := models.DeactivationResult{
+		DryRun:          dryRun,
+		Deactivated:     []string{},
+		NotFound:        []string{},
+		OtherTeam:       []string{},
+		AlreadyInactive: []string{},
+		PullRequests:    []models.ReviewerChange{},
+	}
 	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
-		return err
+		return Deactivation{}, err
 	}
 	defer func() {
 		_ = tx.Rollback(ctx)
 	}()
 
-	_, err = tx.Exec(ctx,
-		`UPDATE users
-         SET is_active = false
-         WHERE team_name=$1 AND user_id = ANY($2)`,
-		teamName, userIDs,
+	found := map[string]bool{}
+	userRows, err := tx.Query(ctx,
+		`SELECT user_id, team_name, is_active FROM users
+         WHERE user_id = ANY($1)
+         ORDER BY user_id
+         FOR UPDATE`,
+		userIDs,
 	)
 	if err != nil {
-		return err
+		return Deactivation{}, err
+	}
+	var toDeactivate []string
+	for userRows.Next() {
+		var uid, team string
+		var isActive bool
+		if err := userRows.Scan(&uid, &team, &isActive); err != nil {
+			userRows.Close()
+			return Deactivation{}, err
+		}
+		found[uid] = true
+		switch {
+		case team != teamName:
+			res.OtherTeam = append(res.OtherTeam, uid)
+		case !isActive:
+			res.AlreadyInactive = append(res.AlreadyInactive, uid)
+		default:
+			toDeactivate = append(toDeactivate, uid)
+		}
+	}
+	userRows.Close()
+	if err := userRows.Err(); err != nil {
+		return Deactivation{}, err
+	}
+	for _, uid := range userIDs {
+		if !found[uid] {
+			found[uid] = true
+			res.NotFound = append(res.NotFound, uid)
+		}
 	}
 
-	activeRows, err := tx.Query(ctx,
-		`SELECT user_id FROM users
-         WHERE team_name=$1 AND is_active=true`,
-		teamName,
-	)
+	if len(toDeactivate) > 0 {
+		_, err := tx.Exec(ctx,
+			`UPDATE users SET is_active = false WHERE user_id = ANY($1)`,
+			toDeactivate,
+		)
+		if err != nil {
+			return Deactivation{}, err
+		}
+		res.Deactivated = toDeactivate
+	}
+
+	for _, uid := range res.Deactivated {
+		err := writeAudit(ctx, tx, "user.deactivate", "user", uid,
+			map[string]bool{"is_active": true}, map[string]bool{"is_active": false})
+		if err != nil {
+			return Deactivation{}, err
+		}
+	}
+
+	var added []AddedReviewer
+	if len(res.Deactivated) > 0 {
+		if added, err = replaceDeactivated(ctx, tx, teamName, &res); err != nil {
+			return Deactivation{}, err
+		}
+	}
+
+	if dryRun {
+		return Deactivation{Result: res}, nil
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Deactivation{}, err
+	}
+	return Deactivation{Result: res, Added: added}, nil
+}
+
+func (s *Store) SetTeamSLA(ctx context.Context, sla models.TeamSLA) error {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return err
 	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
 
-	active := []string{}
-	activeSet := map[string]bool{}
+	before := models.TeamSLA{TeamName: sla.TeamName}
+	var prevHours *int
+	err = tx.QueryRow(ctx,
+		`SELECT sla_hours, sla_policy FROM teams WHERE team_name=$1 FOR UPDATE`,
+		sla.TeamName,
+	).Scan(&prevHours, &before.Policy)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrTeamNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if prevHours != nil {
+		before.SLAHours = *prevHours
+	}
 
-	for activeRows.Next() {
-		var uid string
-		if err := activeRows.Scan(&uid); err != nil {
-			return err
-		}
-		active = append(active, uid)
-		activeSet[uid] = true
-	}
-	activeRows.Close()
-
-	prRows, err := tx.Query(ctx,
-		`SELECT pr.pull_request_id, pr.author_id,
-                COALESCE(array_agg(r.user_id), '{}') AS reviewers
-         FROM pull_requests pr
-         LEFT JOIN pr_reviewers r
-            ON pr.pull_request_id = r.pull_request_id
-         WHERE pr.status='OPEN'
-         GROUP BY pr.pull_request_id`,
+	var hours *int
+	if sla.SLAHours > 0 {
+		hours = &sla.SLAHours
+	}
+	_, err = tx.Exec(ctx,
+		`UPDATE teams SET sla_hours=$1, sla_policy=$2 WHERE team_name=$3`,
+		hours, sla.Policy, sla.TeamName,
 	)
 	if err != nil {
 		return err
 	}
-	defer prRows.Close()
 
-	type PR struct {
-		ID        string
-		Author    string
-		Reviewers []string
+	if err := writeAudit(ctx, tx, "team.set_sla", "team", sla.TeamName, before, sla); err != nil {
+		return err
 	}
 
-	var prs []PR
-	for prRows.Next() {
-		var id, author string
-		var reviewers []string
-		if err := prRows.Scan(&id, &author, &reviewers); err != nil {
-			return err
-		}
+	return tx.Commit(ctx)
+}
 
-		prs = append(prs, PR{id, author, reviewers})
+// GetSLAAssignments returns reviewer assignments on OPEN pull requests whose
+// author's team has an SLA configured. ElapsedHours is left for the caller to
+// fill in. An empty teamName means all teams.
+func (s *Store) GetSLAAssignments(ctx context.Context, teamName string) ([]models.SLABreach, error) {
+	rows, err := s.db.Query(ctx,
+		`SELECT p.pull_request_id, p.pull_request_name, p.author_id, t.team_name,
+                r.user_id, r.assigned_at, r.sla_escalated_at, t.sla_hours, t.sla_policy,
+                EXISTS (SELECT 1 FROM assignment_history h
+                        WHERE h.pull_request_id = p.pull_request_id
+                          AND h.event = 'assigned' AND h.reason = 'sla')
+         FROM pr_reviewers r
+         JOIN pull_requests p ON p.pull_request_id = r.pull_request_id
+         JOIN users a ON a.user_id = p.author_id
+         JOIN teams t ON t.team_name = a.team_name
+         WHERE p.status='OPEN'
+           AND t.sla_hours IS NOT NULL
+           AND ($1 = '' OR t.team_name = $1)
+         ORDER BY r.assigned_at`,
+		teamName,
+	)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	for _, pr := range prs {
-		taken := map[string]bool{}
-		final := []string{}
-
-		for _, r := range pr.Reviewers {
-			taken[r] = true
-			if activeSet[r] {
-				final = append(final, r)
-			}
+	var res []models.SLABreach
+	for rows.Next() {
+		var b models.SLABreach
+		err := rows.Scan(&b.PullRequestID, &b.PullRequestName, &b.AuthorID, &b.TeamName,
+			&b.ReviewerID, &b.AssignedAt, &b.EscalatedAt, &b.SLAHours, &b.Policy, &b.ReviewerAdded)
+		if err != nil {
+			return nil, err
 		}
+		res = append(res, b)
+	}
+	return res, rows.Err()
+}
 
-		if len(final) == 0 {
-			candidates := []string{}
-			for _, u := range active {
-				if u != pr.Author && !taken[u] {
-					candidates = append(candidates, u)
-				}
-			}
-
-			if len(candidates) > 0 {
-				final = append(final, candidates[rand.Intn(len(candidates))])
-			}
-		}
+func (s *Store) MarkSLAEscalated(ctx context.Context, prID, userID string) error {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
 
-		_, err := tx.Exec(ctx,
-			`DELETE FROM pr_reviewers WHERE pull_request_id=$1`,
-			pr.ID,
-		)
+	cmd, err := tx.Exec(ctx,
+		`UPDATE pr_reviewers SET sla_escalated_at=now()
+         WHERE pull_request_id=$1 AND user_id=$2`,
+		prID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() > 0 {
+		err := writeAudit(ctx, tx, "pr.sla_escalate", "pull_request", prID,
+			nil, map[string]string{"reviewer_id": userID})
 		if err != nil {
 			return err
 		}
-
-		for _, r := range final {
-			_, err := tx.Exec(ctx,
-				`INSERT INTO pr_reviewers(pull_request_id, user_id)
-                 VALUES($1,$2)`,
-				pr.ID, r,
-			)
-			if err != nil {
-				return err
-			}
-		}
 	}
 
 	return tx.Commit(ctx)
 }
+
+// AddReviewer assigns one more random active member of the author's team
+// who is not yet reviewing the PR. reason is recorded in the assignment
+// history.
+func (s *Store) AddReviewer(ctx context.Context, prID, reason string) (models.PullRequest, string, error) {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return models.PullRequest{}, "", err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var status, authorID string
+	err = tx.QueryRow(ctx,
+		`SELECT status, author_id FROM pull_requests
+         WHERE pull_request_id=$1 FOR UPDATE`,
+		prID,
+	).Scan(&status, &authorID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.PullRequest{}, "", ErrPRNotFound
+	}
+	if err != nil {
+		return models.PullRequest{}, "", err
+	}
+	if status == "MERGED" {
+		return models.PullRequest{}, "", ErrPRMerged
+	}
+	if status == "CLOSED" {
+		return models.PullRequest{}, "", ErrPRClosed
+	}
+
+	before, err := getPR(ctx, tx, prID)
+	if err != nil {
+		return models.PullRequest{}, "", err
+	}
+
+	var newReviewer string
+	err = tx.QueryRow(ctx,
+		`SELECT u.user_id FROM users u
+         JOIN users a ON a.team_name = u.team_name
+         WHERE a.user_id=$1
+           AND u.is_active=true
+           AND u.user_id <> $1
+           AND NOT EXISTS (
+             SELECT 1 FROM pr_reviewers r
+             WHERE r.pull_request_id=$2 AND r.user_id=u.user_id
+           )
+         ORDER BY random()
+         LIMIT 1`,
+		authorID, prID,
+	).Scan(&newReviewer)
+	if errors.Is(err, pgx.ErrNoRows) {
+		metrics.NoCandidate("add_reviewer")
+		return models.PullRequest{}, "", ErrNoCandidate
+	}
+	if err != nil {
+		return models.PullRequest{}, "", err
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO pr_reviewers(pull_request_id, user_id)
+         VALUES($1,$2)`,
+		prID, newReviewer,
+	)
+	if err != nil {
+		return models.PullRequest{}, "", err
+	}
+	if err := recordAssignment(ctx, tx, prID, EventAssigned, newReviewer, "", reason); err != nil {
+		return models.PullRequest{}, "", err
+	}
+
+	if err := touchPR(ctx, tx, prID); err != nil {
+		return models.PullRequest{}, "", err
+	}
+
+	after, err := getPR(ctx, tx, prID)
+	if err != nil {
+		return models.PullRequest{}, "", err
+	}
+	if err := writeAudit(ctx, tx, "pr.add_reviewer", "pull_request", prID, before, after); err != nil {
+		return models.PullRequest{}, "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.PullRequest{}, "", err
+	}
+
+	return after, newReviewer, nil
+}
@@ -0,0 +1,43 @@
+package tracing
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"Backend-trainee-assignment-autumn-2025/internal/httpx"
+)
+
+// Middleware starts a server span per request, continuing the trace from an
+// incoming traceparent header, and writes the span context back as
+// traceparent on the response.
+func Middleware(route string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prop := otel.GetTextMapPropagator()
+		ctx := prop.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		ctx, span := tracer().Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		prop.Inject(ctx, propagation.HeaderCarrier(w.Header()))
+
+		rec := httpx.NewStatusRecorder(w)
+		h.ServeHTTP(rec, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(rec.Code))
+		if rec.Code >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(rec.Code))
+		}
+	})
+}
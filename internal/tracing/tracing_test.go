@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	prevTP, prevProp := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevProp)
+	})
+	return rec
+}
+
+func TestMiddlewareContinuesTraceparent(t *testing.T) {
+	spans := setupRecorder(t)
+
+	var inner trace.SpanContext
+	h := Middleware("/pullRequest/create", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inner = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/pullRequest/create", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", inner.TraceID().String())
+	require.Contains(t, w.Header().Get("traceparent"), "4bf92f3577b34da6a3ce929d0e0e4736")
+
+	ended := spans.Ended()
+	require.Len(t, ended, 1)
+	require.Equal(t, "POST /pullRequest/create", ended[0].Name())
+	require.Equal(t, "00f067aa0ba902b7", ended[0].Parent().SpanID().String())
+	require.Equal(t, "Error", ended[0].Status().Code.String())
+}
+
+func TestSpanName(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT 1", "db SELECT"},
+		{"\n\t  UPDATE users SET", "db UPDATE"},
+		{"INSERT", "db INSERT"},
+		{"", "db query"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, spanName(tt.sql), tt.sql)
+	}
+}
+
+func TestSetupRejectsUnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), Config{Exporter: "zipkin"})
+	require.Error(t, err)
+}
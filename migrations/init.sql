@@ -1,12 +1,16 @@
 CREATE TABLE IF NOT EXISTS teams (
-                                     team_name TEXT PRIMARY KEY
+                                     team_name TEXT PRIMARY KEY,
+    sla_hours INT NULL CHECK (sla_hours > 0),
+    sla_policy TEXT NOT NULL DEFAULT 'notify' CHECK (sla_policy IN ('notify', 'add_reviewer', 'reassign'))
 );
 
 CREATE TABLE IF NOT EXISTS users (
                                      user_id TEXT PRIMARY KEY,
                                      username TEXT NOT NULL,
                                      team_name TEXT NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
-    is_active BOOLEAN NOT NULL DEFAULT true
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    email TEXT NULL,
+    daily_digest BOOLEAN NOT NULL DEFAULT false
     );
 
 
@@ -16,14 +20,120 @@ CREATE TABLE IF NOT EXISTS pull_requests (
                                              author_id TEXT NOT NULL REFERENCES users(user_id),
     status TEXT NOT NULL DEFAULT 'OPEN',
     created_at TIMESTAMPTZ DEFAULT now(),
-    merged_at TIMESTAMPTZ NULL
+    merged_at TIMESTAMPTZ NULL,
+    last_activity_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    stale_at TIMESTAMPTZ NULL,
+    closed_at TIMESTAMPTZ NULL
     );
 
 CREATE TABLE IF NOT EXISTS pr_reviewers (
                                             pull_request_id TEXT REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
     user_id TEXT REFERENCES users(user_id),
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    sla_escalated_at TIMESTAMPTZ NULL,
     PRIMARY KEY (pull_request_id, user_id)
     );
 
+-- Columns added after the first release: CREATE TABLE IF NOT EXISTS skips
+-- existing tables, so databases created earlier get them here.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email TEXT NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS daily_digest BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS sla_hours INT NULL CHECK (sla_hours > 0);
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS sla_policy TEXT NOT NULL DEFAULT 'notify'
+    CHECK (sla_policy IN ('notify', 'add_reviewer', 'reassign'));
+ALTER TABLE pr_reviewers ADD COLUMN IF NOT EXISTS assigned_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE pr_reviewers ADD COLUMN IF NOT EXISTS sla_escalated_at TIMESTAMPTZ NULL;
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS last_activity_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS stale_at TIMESTAMPTZ NULL;
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS closed_at TIMESTAMPTZ NULL;
+
 CREATE INDEX IF NOT EXISTS idx_pr_reviewers_user ON pr_reviewers(user_id);
 CREATE INDEX IF NOT EXISTS idx_users_team_active ON users(team_name, is_active);
+CREATE INDEX IF NOT EXISTS idx_pull_requests_open_activity ON pull_requests(last_activity_at) WHERE status = 'OPEN';
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_used_at TIMESTAMPTZ NULL,
+    revoked_at TIMESTAMPTZ NULL
+    );
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('admin', 'team_lead')),
+    PRIMARY KEY (user_id, role)
+    );
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    actor TEXT NOT NULL,
+    action TEXT NOT NULL,
+    entity_type TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    before JSONB NULL,
+    after JSONB NULL
+    );
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log(created_at);
+
+-- audit_log is append-only: rows can be inserted but never changed or removed.
+CREATE OR REPLACE FUNCTION audit_log_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_no_change ON audit_log;
+CREATE TRIGGER audit_log_no_change
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_immutable();
+
+DROP TRIGGER IF EXISTS audit_log_no_truncate ON audit_log;
+CREATE TRIGGER audit_log_no_truncate
+    BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_immutable();
+
+CREATE TABLE IF NOT EXISTS assignment_history (
+    id BIGSERIAL PRIMARY KEY,
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    event TEXT NOT NULL CHECK (event IN ('assigned', 'unassigned', 'replaced')),
+    user_id TEXT NOT NULL REFERENCES users(user_id),
+    previous_user_id TEXT NULL REFERENCES users(user_id),
+    reason TEXT NOT NULL CHECK (reason IN ('initial', 'manual_reassign', 'deactivation', 'sla', 'capacity')),
+    actor TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_assignment_history_pr ON assignment_history(pull_request_id, id);
+CREATE INDEX IF NOT EXISTS idx_assignment_history_user ON assignment_history(user_id);
+
+-- idempotency_keys keeps the response of a mutating request sent with an
+-- Idempotency-Key header so that a retry replays it. status_code is NULL
+-- while the first request is still running.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    actor TEXT NOT NULL,
+    key TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    status_code INT NULL,
+    response BYTEA NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (actor, key)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires ON idempotency_keys(expires_at);
+
+-- schema_version records which revision of this file has been applied; the
+-- server's /readyz compares it with storage.SchemaVersion.
+CREATE TABLE IF NOT EXISTS schema_version (
+    version INT PRIMARY KEY,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+INSERT INTO schema_version(version) VALUES (1), (2) ON CONFLICT DO NOTHING;
@@ -0,0 +1,11 @@
+// Package migrations embeds the database schema so the server can bring an
+// existing database up to date when it starts.
+package migrations
+
+import _ "embed"
+
+// Schema is init.sql. Every statement in it may run again on an up-to-date
+// database, so the whole file is applied on each start.
+//
+//go:embed init.sql
+var Schema string
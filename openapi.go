@@ -0,0 +1,8 @@
+// Package api embeds the service's OpenAPI specification so the binary
+// validates traffic against the same openapi.yml that documents it.
+package api
+
+import _ "embed"
+
+//go:embed openapi.yml
+var Spec []byte
@@ -0,0 +1,224 @@
+// Package client is a typed Go client for the PR reviewer assignment HTTP
+// API described in openapi.yml.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client calls the API at a base URL. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	apiKey     string
+	token      string
+}
+
+type Option func(*Client)
+
+// WithHTTPClient replaces http.DefaultClient, e.g. to set a timeout or a
+// custom transport.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithAPIKey authenticates every request with the X-API-Key header.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithBearerToken authenticates every request with a JWT bearer token.
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Error values for the ErrorResponse codes the server sends (/team/add
+// updates an existing team, so TEAM_EXISTS never comes). A returned *Error
+// matches them with errors.Is, e.g. errors.Is(err, client.ErrPRMerged).
+var (
+	ErrPRExists     = errors.New("pull request already exists")
+	ErrPRMerged     = errors.New("pull request is merged")
+	ErrPRClosed     = errors.New("pull request is closed")
+	ErrNotAssigned  = errors.New("reviewer is not assigned")
+	ErrNoCandidate  = errors.New("no active replacement candidate")
+	ErrNotFound     = errors.New("not found")
+	ErrInvalid      = errors.New("invalid request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	// ErrIdempotencyConflict: the Idempotency-Key was used for a different
+	// request, or that request has not finished yet.
+	ErrIdempotencyConflict = errors.New("idempotency key conflict")
+	// ErrSnapshotConflict: a restore in mode "fail" found rows of the archive
+	// that already exist; Error.Details lists them.
+	ErrSnapshotConflict = errors.New("snapshot conflicts with existing data")
+)
+
+var codeErrors = map[string]error{
+	"PR_EXISTS":    ErrPRExists,
+	"PR_MERGED":    ErrPRMerged,
+	"PR_CLOSED":    ErrPRClosed,
+	"NOT_ASSIGNED": ErrNotAssigned,
+	"NO_CANDIDATE": ErrNoCandidate,
+	"NOT_FOUND":    ErrNotFound,
+	"INVALID":      ErrInvalid,
+	"UNAUTHORIZED": ErrUnauthorized,
+	"FORBIDDEN":    ErrForbidden,
+
+	"IDEMPOTENCY_CONFLICT": ErrIdempotencyConflict,
+	"SNAPSHOT_CONFLICT":    ErrSnapshotConflict,
+}
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey returns a context that makes the mutating call made
+// with it send key as Idempotency-Key. Retrying the call with the same key
+// and arguments returns the first response instead of applying it again.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// Error is a non-2xx response carrying the API's ErrorResponse.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	RequestID  string
+	// Details lists the offending fields of an INVALID request, or the
+	// existing rows of a SNAPSHOT_CONFLICT.
+	Details []FieldError
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%d %s: %s", e.StatusCode, e.Code, e.Message)
+	if e.RequestID != "" {
+		msg += " (request " + e.RequestID + ")"
+	}
+	return msg
+}
+
+// Is reports whether target is the error value for e.Code.
+func (e *Error) Is(target error) bool {
+	sentinel, ok := codeErrors[e.Code]
+	return ok && sentinel == target
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	return c.do(ctx, http.MethodGet, path, query, nil, out)
+}
+
+func (c *Client) post(ctx context.Context, path string, in, out interface{}) error {
+	return c.do(ctx, http.MethodPost, path, nil, in, out)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	resp, err := c.send(ctx, method, path, query, in)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return decodeError(resp)
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+// send performs the request and returns the response whatever its status;
+// the caller closes the body.
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, in interface{}) (*http.Response, error) {
+	if in == nil {
+		return c.sendBody(ctx, method, path, query, nil, "")
+	}
+	b, err := json.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+	return c.sendBody(ctx, method, path, query, bytes.NewReader(b), "application/json")
+}
+
+// sendBody is send for a body that is already encoded, or streamed.
+func (c *Client) sendBody(ctx context.Context, method, path string, query url.Values, body io.Reader, contentType string) (*http.Response, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if key, _ := ctx.Value(idempotencyKey{}).(string); key != "" && method == http.MethodPost {
+		req.Header.Set("Idempotency-Key", key)
+	}
+
+	return c.httpClient.Do(req)
+}
+
+func decodeError(resp *http.Response) error {
+	e := &Error{StatusCode: resp.StatusCode, RequestID: resp.Header.Get("X-Request-ID")}
+
+	var er struct {
+		Error struct {
+			Code      string       `json:"code"`
+			Message   string       `json:"message"`
+			RequestID string       `json:"request_id"`
+			Details   []FieldError `json:"details"`
+		} `json:"error"`
+	}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if json.Unmarshal(b, &er) == nil && er.Error.Code != "" {
+		e.Code = er.Error.Code
+		e.Message = er.Error.Message
+		e.Details = er.Error.Details
+		if er.Error.RequestID != "" {
+			e.RequestID = er.Error.RequestID
+		}
+		return e
+	}
+
+	e.Code = http.StatusText(resp.StatusCode)
+	e.Message = strings.TrimSpace(string(b))
+	return e
+}
@@ -0,0 +1,234 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestErrorCodesMatchErrorValues(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", "req-1")
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"error":{"code":"PR_MERGED","message":"cannot reassign on merged PR"}}`))
+	}))
+	defer srv.Close()
+
+	_, err := New(srv.URL).ReassignReviewer(context.Background(), "pr-1", "u1")
+
+	require.ErrorIs(t, err, ErrPRMerged)
+	require.False(t, errors.Is(err, ErrNotAssigned))
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	require.Equal(t, http.StatusConflict, e.StatusCode)
+	require.Equal(t, "req-1", e.RequestID)
+}
+
+func TestInvalidErrorDetails(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"code":"INVALID","message":"author_id: required",` +
+			`"details":[{"field":"author_id","reason":"required"}]}}`))
+	}))
+	defer srv.Close()
+
+	_, err := New(srv.URL).CreatePR(context.Background(), CreatePRRequest{PullRequestID: "pr-1"})
+
+	require.ErrorIs(t, err, ErrInvalid)
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	require.Equal(t, []FieldError{{Field: "author_id", Reason: "required"}}, e.Details)
+}
+
+func TestNonJSONErrorKeepsStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream down", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	err := New(srv.URL).Health(context.Background())
+
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	require.Equal(t, http.StatusBadGateway, e.StatusCode)
+	require.Equal(t, "upstream down", e.Message)
+}
+
+func TestAuthHeaders(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		_, _ = w.Write([]byte(`{"pull_requests":[]}`))
+	}))
+	defer srv.Close()
+
+	_, err := New(srv.URL, WithAPIKey("k1"), WithBearerToken("t1")).ListPRs(context.Background(), PRFilter{})
+	require.NoError(t, err)
+	require.Equal(t, "k1", got.Get("X-API-Key"))
+	require.Equal(t, "Bearer t1", got.Get("Authorization"))
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"error":{"code":"IDEMPOTENCY_CONFLICT","message":"key reused"}}`))
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL)
+	ctx := WithIdempotencyKey(context.Background(), "create-pr-1")
+	_, err := c.CreatePR(ctx, CreatePRRequest{PullRequestID: "pr-1"})
+	require.ErrorIs(t, err, ErrIdempotencyConflict)
+	_, _ = c.GetTeam(ctx, "backend")
+	_, _ = c.CreatePR(context.Background(), CreatePRRequest{PullRequestID: "pr-1"})
+	require.Equal(t, []string{"create-pr-1", "", ""}, keys)
+}
+
+func TestContextCancellation(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := New(srv.URL).GetTeam(ctx, "backend")
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestQueryParameters(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		_, _ = w.Write([]byte(`{"pull_requests":[]}`))
+	}))
+	defer srv.Close()
+
+	stale := true
+	_, err := New(srv.URL).GetReview(context.Background(), "u 1", PRFilter{Status: "OPEN", Stale: &stale})
+	require.NoError(t, err)
+	require.Equal(t, "stale=true&status=OPEN&user_id=u+1", query)
+
+	_, err = New(srv.URL).Stats(context.Background(), StatsFilter{TeamName: "backend", From: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)})
+	require.NoError(t, err)
+	require.Equal(t, "from=2025-10-01T00%3A00%3A00Z&team_name=backend", query)
+}
+
+// TestCoversSpec fails when openapi.yml gains a path the client never calls.
+func TestCoversSpec(t *testing.T) {
+	b, err := os.ReadFile("../../openapi.yml")
+	require.NoError(t, err)
+	var spec struct {
+		Paths map[string]interface{} `yaml:"paths"`
+	}
+	require.NoError(t, yaml.Unmarshal(b, &spec))
+
+	var src strings.Builder
+	for _, f := range []string{"client.go", "operations.go"} {
+		b, err := os.ReadFile(f)
+		require.NoError(t, err)
+		src.Write(b)
+	}
+	for path := range spec.Paths {
+		if path == "/health" {
+			continue // alias of /livez
+		}
+		require.Regexp(t, regexp.MustCompile(`"`+regexp.QuoteMeta(path)+`"`), src.String(), "no client method for %s", path)
+	}
+}
+
+func TestImportPRs(t *testing.T) {
+	var contentType, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte(`{"index":0,"line":1,"pull_request_id":"pr-1","pr":{"pull_request_id":"pr-1","assigned_reviewers":["u2"]}}
+{"index":1,"line":2,"pull_request_id":"pr-2","error":{"code":"PR_EXISTS","message":"PR id already exists"}}
+{"error":{"code":"ERROR","message":"internal error","request_id":"req-1"}}
+`))
+	}))
+	defer srv.Close()
+
+	in := `{"pull_request_id":"pr-1"}` + "\n" + `{"pull_request_id":"pr-2"}` + "\n"
+	var got []BatchPRResult
+	err := New(srv.URL).ImportPRs(context.Background(), strings.NewReader(in), func(r BatchPRResult) error {
+		got = append(got, r)
+		return nil
+	})
+
+	require.Equal(t, "application/x-ndjson", contentType)
+	require.Equal(t, in, body)
+	require.Len(t, got, 2)
+	require.Equal(t, []string{"u2"}, got[0].PR.AssignedReviewers)
+	require.Equal(t, 1, got[1].Index)
+	require.ErrorIs(t, got[1].Error.Err(), ErrPRExists)
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	require.Equal(t, "req-1", e.RequestID)
+}
+
+func TestRestoreSnapshot(t *testing.T) {
+	var query, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"error":{"code":"SNAPSHOT_CONFLICT","message":"1 rows of the archive already exist",` +
+			`"details":[{"field":"teams.0.team_name","reason":"team backend already exists"}]}}`))
+	}))
+	defer srv.Close()
+
+	_, err := New(srv.URL).RestoreSnapshot(context.Background(), strings.NewReader(`{"version":1}`), "fail")
+	require.ErrorIs(t, err, ErrSnapshotConflict)
+	require.Equal(t, "mode=fail", query)
+	require.Equal(t, `{"version":1}`, body)
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	require.Equal(t, "teams.0.team_name", e.Details[0].Field)
+}
+
+func TestDeactivateUsers(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"dry_run":true,"deactivated":["u2"],"not_found":["u9"],"other_team":["u5"],"already_inactive":[],` +
+			`"pull_requests":[{"pull_request_id":"pr-1","removed_reviewers":["u2"],"added_reviewer":"u3","left_without_reviewers":false},` +
+			`{"pull_request_id":"pr-2","removed_reviewers":["u2"],"left_without_reviewers":true,"warning":"no reviewers"}]}`))
+	}))
+	defer srv.Close()
+
+	res, err := New(srv.URL).DeactivateUsersDryRun(context.Background(), "backend", []string{"u2", "u5", "u9"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"team_name":"backend","user_ids":["u2","u5","u9"],"dry_run":true}`, body)
+	require.True(t, res.DryRun)
+	require.Equal(t, []string{"u9"}, res.NotFound)
+	require.Equal(t, []string{"u5"}, res.OtherTeam)
+	require.Equal(t, []ReviewerChange{
+		{PullRequestID: "pr-1", RemovedReviewers: []string{"u2"}, AddedReviewer: "u3"},
+		{PullRequestID: "pr-2", RemovedReviewers: []string{"u2"}, LeftWithoutReviewers: true, Warning: "no reviewers"},
+	}, res.PullRequests)
+
+	_, err = New(srv.URL).DeactivateUsers(context.Background(), "backend", []string{"u2"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"team_name":"backend","user_ids":["u2"]}`, body)
+}
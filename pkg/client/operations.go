@@ -0,0 +1,477 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// AddTeam creates a team or updates its members.
+func (c *Client) AddTeam(ctx context.Context, t Team) (Team, error) {
+	var resp struct {
+		Team Team `json:"team"`
+	}
+	err := c.post(ctx, "/team/add", t, &resp)
+	return resp.Team, err
+}
+
+// GetTeam returns a team with all its members.
+func (c *Client) GetTeam(ctx context.Context, teamName string) (Team, error) {
+	var t Team
+	err := c.get(ctx, "/team/get", url.Values{"team_name": {teamName}}, &t)
+	return t, err
+}
+
+// SetUserActive activates or deactivates a user.
+func (c *Client) SetUserActive(ctx context.Context, userID string, active bool) (User, error) {
+	var resp struct {
+		User User `json:"user"`
+	}
+	err := c.post(ctx, "/users/setIsActive", map[string]interface{}{
+		"user_id":   userID,
+		"is_active": active,
+	}, &resp)
+	return resp.User, err
+}
+
+// SetNotifications sets a user's email and daily digest subscription.
+func (c *Client) SetNotifications(ctx context.Context, userID, email string, dailyDigest bool) (User, error) {
+	var resp struct {
+		User User `json:"user"`
+	}
+	err := c.post(ctx, "/users/setNotifications", map[string]interface{}{
+		"user_id":      userID,
+		"email":        email,
+		"daily_digest": dailyDigest,
+	}, &resp)
+	return resp.User, err
+}
+
+// SetRoles replaces the roles stored for a user.
+func (c *Client) SetRoles(ctx context.Context, userID string, roles []string) error {
+	return c.post(ctx, "/users/setRoles", map[string]interface{}{
+		"user_id": userID,
+		"roles":   roles,
+	}, nil)
+}
+
+// GetReview returns the pull requests userID is assigned to review.
+func (c *Client) GetReview(ctx context.Context, userID string, f PRFilter) ([]PullRequestShort, error) {
+	q := f.query()
+	q.Set("user_id", userID)
+	var resp struct {
+		PullRequests []PullRequestShort `json:"pull_requests"`
+	}
+	err := c.get(ctx, "/users/getReview", q, &resp)
+	return resp.PullRequests, err
+}
+
+// CreatePR creates a pull request; the server assigns its reviewers.
+func (c *Client) CreatePR(ctx context.Context, req CreatePRRequest) (PullRequest, error) {
+	var resp struct {
+		PR PullRequest `json:"pr"`
+	}
+	err := c.post(ctx, "/pullRequest/create", req, &resp)
+	return resp.PR, err
+}
+
+// MergePR marks a pull request MERGED. Merging twice is not an error.
+func (c *Client) MergePR(ctx context.Context, prID string) (PullRequest, error) {
+	var resp struct {
+		PR PullRequest `json:"pr"`
+	}
+	err := c.post(ctx, "/pullRequest/merge", map[string]string{"pull_request_id": prID}, &resp)
+	return resp.PR, err
+}
+
+// ReassignReviewer replaces oldUserID on the pull request with another
+// active member of that reviewer's team.
+func (c *Client) ReassignReviewer(ctx context.Context, prID, oldUserID string) (ReassignResult, error) {
+	var resp ReassignResult
+	err := c.post(ctx, "/pullRequest/reassign", map[string]string{
+		"pull_request_id": prID,
+		"old_user_id":     oldUserID,
+	}, &resp)
+	return resp, err
+}
+
+// ReassignReviewerDryRun tells how ReassignReviewer would change the pull
+// request without saving it or notifying anyone.
+func (c *Client) ReassignReviewerDryRun(ctx context.Context, prID, oldUserID string) (ReassignResult, error) {
+	var resp ReassignResult
+	err := c.post(ctx, "/pullRequest/reassign", map[string]interface{}{
+		"pull_request_id": prID,
+		"old_user_id":     oldUserID,
+		"dry_run":         true,
+	}, &resp)
+	return resp, err
+}
+
+// BatchCreatePRs creates up to 1000 pull requests in one request. Items
+// that already exist or whose author is unknown come back in Failed; a
+// malformed item fails the whole call with ErrInvalid.
+func (c *Client) BatchCreatePRs(ctx context.Context, reqs []CreatePRRequest) (BatchPRResponse, error) {
+	var resp BatchPRResponse
+	err := c.post(ctx, "/pullRequest/batchCreate", reqs, &resp)
+	return resp, err
+}
+
+// ImportPRs streams newline-delimited CreatePRRequest objects from r to the
+// server and calls fn with each result as the server commits it, in input
+// order. An error that stops the import midway is returned as *Error; the
+// results already passed to fn stay committed.
+func (c *Client) ImportPRs(ctx context.Context, r io.Reader, fn func(BatchPRResult) error) error {
+	resp, err := c.sendBody(ctx, http.MethodPost, "/pullRequest/import", nil, r, "application/x-ndjson")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return decodeError(resp)
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var line struct {
+			BatchPRResult
+			// Only the final error line has no index.
+			Index *int `json:"index"`
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("decode /pullRequest/import response: %w", err)
+		}
+		if err := json.Unmarshal(raw, &line); err != nil {
+			return fmt.Errorf("decode /pullRequest/import response: %w", err)
+		}
+		if line.Index == nil {
+			var er struct {
+				Error struct {
+					Code      string `json:"code"`
+					Message   string `json:"message"`
+					RequestID string `json:"request_id"`
+				} `json:"error"`
+			}
+			_ = json.Unmarshal(raw, &er)
+			return &Error{StatusCode: resp.StatusCode, Code: er.Error.Code, Message: er.Error.Message, RequestID: er.Error.RequestID}
+		}
+		line.BatchPRResult.Index = *line.Index
+		if err := fn(line.BatchPRResult); err != nil {
+			return err
+		}
+	}
+}
+
+// Timeline returns the assignment events of a pull request in order.
+func (c *Client) Timeline(ctx context.Context, prID string) ([]AssignmentEvent, error) {
+	var resp struct {
+		Events []AssignmentEvent `json:"events"`
+	}
+	err := c.get(ctx, "/pullRequest/timeline", url.Values{"pull_request_id": {prID}}, &resp)
+	return resp.Events, err
+}
+
+// ListPRs lists pull requests matching f.
+func (c *Client) ListPRs(ctx context.Context, f PRFilter) ([]PullRequestShort, error) {
+	var resp struct {
+		PullRequests []PullRequestShort `json:"pull_requests"`
+	}
+	err := c.get(ctx, "/pullRequest/list", f.query(), &resp)
+	return resp.PullRequests, err
+}
+
+// DeactivateUsers deactivates users of a team and replaces them as
+// reviewers on open pull requests. The result also lists the IDs that were
+// not found, belong to another team or were already inactive.
+func (c *Client) DeactivateUsers(ctx context.Context, teamName string, userIDs []string) (DeactivationResult, error) {
+	var r DeactivationResult
+	err := c.post(ctx, "/team/deactivateUsers", map[string]interface{}{
+		"team_name": teamName,
+		"user_ids":  userIDs,
+	}, &r)
+	return r, err
+}
+
+// DeactivateUsersDryRun tells what DeactivateUsers would change without
+// changing anything. Replacements are picked at random, so a real run may
+// choose others.
+func (c *Client) DeactivateUsersDryRun(ctx context.Context, teamName string, userIDs []string) (DeactivationResult, error) {
+	var r DeactivationResult
+	err := c.post(ctx, "/team/deactivateUsers", map[string]interface{}{
+		"team_name": teamName,
+		"user_ids":  userIDs,
+		"dry_run":   true,
+	}, &r)
+	return r, err
+}
+
+// SetSLA sets a team's review SLA and escalation policy.
+func (c *Client) SetSLA(ctx context.Context, sla TeamSLA) (TeamSLA, error) {
+	var resp struct {
+		SLA TeamSLA `json:"sla"`
+	}
+	err := c.post(ctx, "/team/setSLA", sla, &resp)
+	return resp.SLA, err
+}
+
+// SLABreaches lists current SLA breaches, of one team if teamName is set.
+func (c *Client) SLABreaches(ctx context.Context, teamName string) ([]SLABreach, error) {
+	q := url.Values{}
+	if teamName != "" {
+		q.Set("team_name", teamName)
+	}
+	var resp struct {
+		Breaches []SLABreach `json:"breaches"`
+	}
+	err := c.get(ctx, "/sla/breaches", q, &resp)
+	return resp.Breaches, err
+}
+
+// CreateAPIKey creates an API key. The plaintext key is returned only here.
+func (c *Client) CreateAPIKey(ctx context.Context, name string, scopes []string) (APIKey, string, error) {
+	var resp struct {
+		APIKey APIKey `json:"api_key"`
+		Key    string `json:"key"`
+	}
+	err := c.post(ctx, "/admin/apiKeys/create", map[string]interface{}{
+		"name":   name,
+		"scopes": scopes,
+	}, &resp)
+	return resp.APIKey, resp.Key, err
+}
+
+func (c *Client) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	var resp struct {
+		APIKeys []APIKey `json:"api_keys"`
+	}
+	err := c.get(ctx, "/admin/apiKeys/list", nil, &resp)
+	return resp.APIKeys, err
+}
+
+func (c *Client) RevokeAPIKey(ctx context.Context, id string) error {
+	return c.post(ctx, "/admin/apiKeys/revoke", map[string]string{"id": id}, nil)
+}
+
+// Audit returns one page of the audit log, newest first.
+func (c *Client) Audit(ctx context.Context, f AuditFilter) (AuditPage, error) {
+	q := url.Values{}
+	for k, v := range map[string]string{
+		"actor":       f.Actor,
+		"action":      f.Action,
+		"entity_type": f.EntityType,
+		"entity_id":   f.EntityID,
+		"cursor":      f.Cursor,
+	} {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	if !f.From.IsZero() {
+		q.Set("from", f.From.Format(time.RFC3339))
+	}
+	if !f.To.IsZero() {
+		q.Set("to", f.To.Format(time.RFC3339))
+	}
+	if f.Limit > 0 {
+		q.Set("limit", strconv.Itoa(f.Limit))
+	}
+	var page AuditPage
+	err := c.get(ctx, "/audit", q, &page)
+	return page, err
+}
+
+// Stats returns assignment counts, per-user load, time-to-merge and
+// time-to-first-review, overall and per team.
+func (c *Client) Stats(ctx context.Context, f StatsFilter) (Stats, error) {
+	q := url.Values{}
+	if f.TeamName != "" {
+		q.Set("team_name", f.TeamName)
+	}
+	if !f.From.IsZero() {
+		q.Set("from", f.From.Format(time.RFC3339))
+	}
+	if !f.To.IsZero() {
+		q.Set("to", f.To.Format(time.RFC3339))
+	}
+	var s Stats
+	err := c.get(ctx, "/stats", q, &s)
+	return s, err
+}
+
+// Fairness reports how evenly the assignments made between from and to
+// (zero for unbounded) were spread over teamName's active members.
+func (c *Client) Fairness(ctx context.Context, teamName string, from, to time.Time) (FairnessReport, error) {
+	q := url.Values{"team_name": {teamName}}
+	if !from.IsZero() {
+		q.Set("from", from.Format(time.RFC3339))
+	}
+	if !to.IsZero() {
+		q.Set("to", to.Format(time.RFC3339))
+	}
+	var r FairnessReport
+	err := c.get(ctx, "/team/fairness", q, &r)
+	return r, err
+}
+
+// Rebalance moves open reviews from the most to the least loaded active
+// members of teamName, at most maxMoves of them (0 for the server's limit).
+func (c *Client) Rebalance(ctx context.Context, teamName string, maxMoves int) (RebalanceResult, error) {
+	body := map[string]interface{}{"team_name": teamName}
+	if maxMoves > 0 {
+		body["max_moves"] = maxMoves
+	}
+	var r RebalanceResult
+	err := c.post(ctx, "/team/rebalance", body, &r)
+	return r, err
+}
+
+// ExportUsers streams all users with their teams. The caller reads and
+// closes the returned body: NDJSON lines decode into User.
+func (c *Client) ExportUsers(ctx context.Context, f ExportFilter) (io.ReadCloser, error) {
+	return c.export(ctx, "/export/users", f)
+}
+
+// ExportPRs streams pull requests; NDJSON lines decode into PRRecord.
+func (c *Client) ExportPRs(ctx context.Context, f ExportFilter) (io.ReadCloser, error) {
+	return c.export(ctx, "/export/pullRequests", f)
+}
+
+// ExportReviewers streams current reviewer assignments; NDJSON lines decode
+// into ReviewerRecord.
+func (c *Client) ExportReviewers(ctx context.Context, f ExportFilter) (io.ReadCloser, error) {
+	return c.export(ctx, "/export/reviewers", f)
+}
+
+// export starts a streamed download. A server error after the first rows
+// shows as a final ErrorResponse line (NDJSON) or as a read error on the
+// body (CSV).
+func (c *Client) export(ctx context.Context, path string, f ExportFilter) (io.ReadCloser, error) {
+	q := url.Values{}
+	if f.TeamName != "" {
+		q.Set("team_name", f.TeamName)
+	}
+	if !f.From.IsZero() {
+		q.Set("from", f.From.Format(time.RFC3339))
+	}
+	if !f.To.IsZero() {
+		q.Set("to", f.To.Format(time.RFC3339))
+	}
+	if f.Format != "" {
+		q.Set("format", f.Format)
+	}
+	resp, err := c.send(ctx, http.MethodGet, path, q, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, decodeError(resp)
+	}
+	return resp.Body, nil
+}
+
+// Snapshot streams the JSON archive of all teams, users, pull requests,
+// reviewers and assignment history. The caller reads and closes the body; a
+// server failure midway shows as a read error.
+func (c *Client) Snapshot(ctx context.Context) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, http.MethodGet, "/admin/snapshot", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, decodeError(resp)
+	}
+	return resp.Body, nil
+}
+
+// RestoreSnapshot loads an archive read from r. mode is "fail" (the
+// default), "skip" or "overwrite" and decides what happens to rows that
+// already exist; in mode "fail" they are reported as ErrSnapshotConflict.
+// Nothing is written unless the whole archive is.
+func (c *Client) RestoreSnapshot(ctx context.Context, r io.Reader, mode string) (RestoreResult, error) {
+	var res RestoreResult
+	q := url.Values{}
+	if mode != "" {
+		q.Set("mode", mode)
+	}
+	resp, err := c.sendBody(ctx, http.MethodPost, "/admin/snapshot/restore", q, r, "application/json")
+	if err != nil {
+		return res, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return res, decodeError(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return res, fmt.Errorf("decode /admin/snapshot/restore response: %w", err)
+	}
+	return res, nil
+}
+
+// Health reports whether the server answers its liveness probe.
+func (c *Client) Health(ctx context.Context) error {
+	return c.get(ctx, "/livez", nil, nil)
+}
+
+// Ready returns the readiness checks. A server that is not ready answers
+// 503 with the same body, so the checks are returned along with the error.
+func (c *Client) Ready(ctx context.Context) (Readiness, error) {
+	var r Readiness
+	resp, err := c.send(ctx, http.MethodGet, "/readyz", nil, nil)
+	if err != nil {
+		return r, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+		return r, decodeError(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return r, fmt.Errorf("decode /readyz response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return r, &Error{StatusCode: resp.StatusCode, Code: "UNAVAILABLE", Message: "not ready"}
+	}
+	return r, nil
+}
+
+// Metrics returns the Prometheus text exposition from /metrics.
+func (c *Client) Metrics(ctx context.Context) (string, error) {
+	resp, err := c.send(ctx, http.MethodGet, "/metrics", nil, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return "", decodeError(resp)
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("read /metrics response: %w", err)
+	}
+	return string(b), nil
+}
+
+func (f PRFilter) query() url.Values {
+	q := url.Values{}
+	if f.Status != "" {
+		q.Set("status", f.Status)
+	}
+	if f.Stale != nil {
+		q.Set("stale", strconv.FormatBool(*f.Stale))
+	}
+	if f.AuthorID != "" {
+		q.Set("author_id", f.AuthorID)
+	}
+	if f.TeamName != "" {
+		q.Set("team_name", f.TeamName)
+	}
+	return q
+}
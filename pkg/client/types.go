@@ -0,0 +1,339 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// FieldError is one entry of an INVALID error's details.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+type TeamMember struct {
+	UserID      string `json:"user_id"`
+	Username    string `json:"username"`
+	IsActive    bool   `json:"is_active"`
+	Email       string `json:"email,omitempty"`
+	DailyDigest bool   `json:"daily_digest,omitempty"`
+}
+
+type Team struct {
+	TeamName string       `json:"team_name"`
+	Members  []TeamMember `json:"members"`
+}
+
+type User struct {
+	UserID      string `json:"user_id"`
+	Username    string `json:"username"`
+	TeamName    string `json:"team_name"`
+	IsActive    bool   `json:"is_active"`
+	Email       string `json:"email,omitempty"`
+	DailyDigest bool   `json:"daily_digest,omitempty"`
+}
+
+type PullRequest struct {
+	PullRequestID     string     `json:"pull_request_id"`
+	PullRequestName   string     `json:"pull_request_name"`
+	AuthorID          string     `json:"author_id"`
+	Status            string     `json:"status"`
+	AssignedReviewers []string   `json:"assigned_reviewers"`
+	CreatedAt         *time.Time `json:"created_at,omitempty"`
+	MergedAt          *time.Time `json:"merged_at,omitempty"`
+	IsStale           bool       `json:"is_stale"`
+	StaleSince        *time.Time `json:"stale_since,omitempty"`
+	ClosedAt          *time.Time `json:"closed_at,omitempty"`
+}
+
+type PullRequestShort struct {
+	PullRequestID   string `json:"pull_request_id"`
+	PullRequestName string `json:"pull_request_name"`
+	AuthorID        string `json:"author_id"`
+	Status          string `json:"status"`
+	IsStale         bool   `json:"is_stale"`
+}
+
+type CreatePRRequest struct {
+	PullRequestID   string `json:"pull_request_id"`
+	PullRequestName string `json:"pull_request_name"`
+	AuthorID        string `json:"author_id"`
+}
+
+// BatchPRResult is the outcome of one pull request of BatchCreatePRs or
+// ImportPRs: PR if it was created, Error otherwise.
+type BatchPRResult struct {
+	Index int `json:"index"`
+	// Line is the 1-based input line; set by ImportPRs only.
+	Line          int          `json:"line,omitempty"`
+	PullRequestID string       `json:"pull_request_id,omitempty"`
+	PR            *PullRequest `json:"pr,omitempty"`
+	Error         *ItemError   `json:"error,omitempty"`
+}
+
+// ItemError is why one item of a batch failed. Err converts it to an
+// *Error, so it matches the same error values, e.g. ErrPRExists.
+type ItemError struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"`
+}
+
+func (e *ItemError) Err() error {
+	return &Error{Code: e.Code, Message: e.Message, Details: e.Details}
+}
+
+type BatchPRResponse struct {
+	Created []BatchPRResult `json:"created"`
+	Failed  []BatchPRResult `json:"failed"`
+}
+
+type ReassignResult struct {
+	PR         PullRequest `json:"pr"`
+	ReplacedBy string      `json:"replaced_by"`
+	// DryRun is set by ReassignReviewerDryRun; nothing was saved.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// PRFilter narrows PR listings; zero values are not sent.
+type PRFilter struct {
+	// Status is OPEN, MERGED or CLOSED.
+	Status string
+	Stale  *bool
+	// AuthorID and TeamName apply to ListPRs only.
+	AuthorID string
+	TeamName string
+}
+
+// ExportFilter narrows an export; zero values are not sent. From and To
+// apply to pull requests and reviewers only.
+type ExportFilter struct {
+	TeamName string
+	From, To time.Time
+	// Format is ndjson (the default) or csv.
+	Format string
+}
+
+// PRRecord is one line of an NDJSON pull request export.
+type PRRecord struct {
+	PullRequestID   string     `json:"pull_request_id"`
+	PullRequestName string     `json:"pull_request_name"`
+	AuthorID        string     `json:"author_id"`
+	TeamName        string     `json:"team_name"`
+	Status          string     `json:"status"`
+	CreatedAt       time.Time  `json:"created_at"`
+	MergedAt        *time.Time `json:"merged_at,omitempty"`
+	StaleAt         *time.Time `json:"stale_at,omitempty"`
+	ClosedAt        *time.Time `json:"closed_at,omitempty"`
+}
+
+// ReviewerRecord is one line of an NDJSON reviewer export.
+type ReviewerRecord struct {
+	PullRequestID  string     `json:"pull_request_id"`
+	UserID         string     `json:"user_id"`
+	TeamName       string     `json:"team_name"`
+	AssignedAt     time.Time  `json:"assigned_at"`
+	SLAEscalatedAt *time.Time `json:"sla_escalated_at,omitempty"`
+}
+
+// StatsFilter narrows /stats; zero values are not sent. TeamName is the
+// reviewer's team for assignments and the author's team for pull requests.
+type StatsFilter struct {
+	TeamName string
+	From, To time.Time
+}
+
+type Stats struct {
+	ReviewerAssignments map[string]int `json:"reviewer_assignments"`
+	PRAssignments       map[string]int `json:"pr_assignments"`
+	Users               []UserStats    `json:"users"`
+	TimeToMerge         DurationStats  `json:"time_to_merge"`
+	TimeToFirstReview   DurationStats  `json:"time_to_first_review"`
+	Teams               []TeamStats    `json:"teams"`
+}
+
+type UserStats struct {
+	UserID           string `json:"user_id"`
+	Username         string `json:"username"`
+	TeamName         string `json:"team_name"`
+	IsActive         bool   `json:"is_active"`
+	OpenAssignments  int    `json:"open_assignments"`
+	TotalAssignments int    `json:"total_assignments"`
+}
+
+// DurationStats summarizes Count durations; the percentiles are nil when
+// Count is 0.
+type DurationStats struct {
+	Count         int      `json:"count"`
+	MedianSeconds *float64 `json:"median_seconds,omitempty"`
+	P90Seconds    *float64 `json:"p90_seconds,omitempty"`
+}
+
+type TeamStats struct {
+	TeamName          string        `json:"team_name"`
+	ActiveMembers     int           `json:"active_members"`
+	OpenAssignments   int           `json:"open_assignments"`
+	TotalAssignments  int           `json:"total_assignments"`
+	PullRequests      int           `json:"pull_requests"`
+	Merged            int           `json:"merged"`
+	TimeToMerge       DurationStats `json:"time_to_merge"`
+	TimeToFirstReview DurationStats `json:"time_to_first_review"`
+}
+
+type TeamSLA struct {
+	TeamName string `json:"team_name"`
+	SLAHours int    `json:"sla_hours"`
+	// Policy is notify (the default), add_reviewer or reassign.
+	Policy string `json:"policy,omitempty"`
+}
+
+type SLABreach struct {
+	PullRequestID   string     `json:"pull_request_id"`
+	PullRequestName string     `json:"pull_request_name"`
+	AuthorID        string     `json:"author_id"`
+	TeamName        string     `json:"team_name"`
+	ReviewerID      string     `json:"reviewer_id"`
+	AssignedAt      time.Time  `json:"assigned_at"`
+	SLAHours        int        `json:"sla_hours"`
+	ElapsedHours    float64    `json:"elapsed_working_hours"`
+	Policy          string     `json:"policy"`
+	EscalatedAt     *time.Time `json:"escalated_at,omitempty"`
+}
+
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+type AssignmentEvent struct {
+	ID             int64     `json:"id"`
+	PullRequestID  string    `json:"pull_request_id"`
+	Event          string    `json:"event"`
+	UserID         string    `json:"user_id"`
+	PreviousUserID string    `json:"previous_user_id,omitempty"`
+	Reason         string    `json:"reason"`
+	Actor          string    `json:"actor"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+}
+
+// AuditFilter narrows GET /audit; zero values are not sent.
+type AuditFilter struct {
+	Actor      string
+	Action     string
+	EntityType string
+	EntityID   string
+	From, To   time.Time
+	Limit      int
+	// Cursor is NextCursor from the previous page.
+	Cursor string
+}
+
+type AuditPage struct {
+	Entries    []AuditEntry `json:"entries"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+type Readiness struct {
+	Status string          `json:"status"`
+	Checks ReadinessChecks `json:"checks"`
+}
+
+type ReadinessChecks struct {
+	Database   string            `json:"database,omitempty"`
+	Migrations *MigrationsStatus `json:"migrations,omitempty"`
+	Shutdown   string            `json:"shutdown,omitempty"`
+}
+
+type MigrationsStatus struct {
+	Status   string `json:"status"`
+	Applied  int    `json:"applied"`
+	Expected int    `json:"expected"`
+}
+
+type MemberLoad struct {
+	UserID          string `json:"user_id"`
+	Username        string `json:"username"`
+	Assignments     int    `json:"assignments"`
+	OpenAssignments int    `json:"open_assignments"`
+}
+
+type FairnessReport struct {
+	TeamName         string     `json:"team_name"`
+	From             *time.Time `json:"from,omitempty"`
+	To               *time.Time `json:"to,omitempty"`
+	ActiveMembers    int        `json:"active_members"`
+	TotalAssignments int        `json:"total_assignments"`
+	Mean             float64    `json:"mean"`
+	StdDev           float64    `json:"stddev"`
+	Gini             float64    `json:"gini"`
+	// MaxMinRatio is nil when some member has no assignments.
+	MaxMinRatio *float64     `json:"max_min_ratio,omitempty"`
+	Members     []MemberLoad `json:"members"`
+	Overloaded  []MemberLoad `json:"overloaded"`
+	Underloaded []MemberLoad `json:"underloaded"`
+}
+
+type RebalanceMove struct {
+	PullRequestID string `json:"pull_request_id"`
+	FromUserID    string `json:"from_user_id"`
+	ToUserID      string `json:"to_user_id"`
+}
+
+type RebalanceResult struct {
+	TeamName string          `json:"team_name"`
+	Moves    []RebalanceMove `json:"moves"`
+	Before   map[string]int  `json:"open_before"`
+	After    map[string]int  `json:"open_after"`
+}
+
+// DeactivationResult sorts the requested IDs by outcome and lists the open
+// pull requests whose reviewers changed.
+type DeactivationResult struct {
+	DryRun          bool             `json:"dry_run"`
+	Deactivated     []string         `json:"deactivated"`
+	NotFound        []string         `json:"not_found"`
+	OtherTeam       []string         `json:"other_team"`
+	AlreadyInactive []string         `json:"already_inactive"`
+	PullRequests    []ReviewerChange `json:"pull_requests"`
+}
+
+// ReviewerChange is how the reviewers of one pull request changed. Warning
+// is set when it was left without reviewers.
+type ReviewerChange struct {
+	PullRequestID        string   `json:"pull_request_id"`
+	RemovedReviewers     []string `json:"removed_reviewers"`
+	AddedReviewer        string   `json:"added_reviewer,omitempty"`
+	LeftWithoutReviewers bool     `json:"left_without_reviewers"`
+	Warning              string   `json:"warning,omitempty"`
+}
+
+// RestoreCounts tells how many rows of one kind a restore wrote.
+type RestoreCounts struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+}
+
+type RestoreResult struct {
+	Mode         string        `json:"mode"`
+	Teams        RestoreCounts `json:"teams"`
+	Users        RestoreCounts `json:"users"`
+	PullRequests RestoreCounts `json:"pull_requests"`
+	Reviewers    RestoreCounts `json:"reviewers"`
+	History      RestoreCounts `json:"history"`
+}
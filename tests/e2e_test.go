@@ -1,13 +1,14 @@
 package tests
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"net/http"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"Backend-trainee-assignment-autumn-2025/pkg/client"
 )
 
 var baseURL = detectURL()
@@ -19,19 +20,16 @@ func detectURL() string {
 	}
 
 	for _, u := range candidates {
-		resp, err := http.Get(u + "/health")
-		if err == nil && resp.StatusCode == 200 {
+		if client.New(u).Health(context.Background()) == nil {
 			return u
 		}
 	}
 	return "http://localhost:8080"
 }
 
-func waitForServer(t *testing.T) {
+func waitForServer(t *testing.T, c *client.Client) {
 	for i := 0; i < 60; i++ {
-		resp, err := http.Get(baseURL + "/health")
-		if err == nil && resp.StatusCode == 200 {
-			resp.Body.Close()
+		if c.Health(context.Background()) == nil {
 			return
 		}
 		time.Sleep(200 * time.Millisecond)
@@ -39,87 +37,47 @@ func waitForServer(t *testing.T) {
 	t.Fatal("server did not become ready at " + baseURL)
 }
 
-func post(t *testing.T, path string, body interface{}) *http.Response {
-	b, _ := json.Marshal(body)
-	resp, err := http.Post(baseURL+path, "application/json", bytes.NewBuffer(b))
-	require.NoError(t, err)
-	return resp
-}
-
-func get(t *testing.T, path string) *http.Response {
-	resp, err := http.Get(baseURL + path)
-	require.NoError(t, err)
-	return resp
-}
-
-func decode(t *testing.T, resp *http.Response, v interface{}) {
-	defer resp.Body.Close()
-	require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
-}
-
 func Test_FullFlow(t *testing.T) {
-	waitForServer(t)
+	ctx := context.Background()
+	c := client.New(baseURL)
+	waitForServer(t, c)
 
-	_ = post(t, "/debug/reset", nil)
+	if resp, err := http.Post(baseURL+"/debug/reset", "application/json", nil); err == nil {
+		resp.Body.Close()
+	}
 
-	resp := post(t, "/team/add", map[string]interface{}{
-		"team_name": "test_command",
-		"members": []map[string]interface{}{
-			{"user_id": "u1", "username": "alice", "is_active": true},
-			{"user_id": "u2", "username": "bob", "is_active": true},
-			{"user_id": "u3", "username": "charlie", "is_active": true},
-			{"user_id": "u4", "username": "vasya", "is_active": true},
+	_, err := c.AddTeam(ctx, client.Team{
+		TeamName: "test_command",
+		Members: []client.TeamMember{
+			{UserID: "u1", Username: "alice", IsActive: true},
+			{UserID: "u2", Username: "bob", IsActive: true},
+			{UserID: "u3", Username: "charlie", IsActive: true},
+			{UserID: "u4", Username: "vasya", IsActive: true},
 		},
 	})
-	require.Equal(t, 201, resp.StatusCode)
-
-	resp = post(t, "/pullRequest/create", map[string]interface{}{
-		"pull_request_id":   "test_pr1",
-		"pull_request_name": "New login",
-		"author_id":         "u1",
-	})
-	require.Equal(t, 201, resp.StatusCode)
-
-	var prResp struct {
-		PR struct {
-			ID        string   `json:"pull_request_id"`
-			Reviewers []string `json:"assigned_reviewers"`
-		} `json:"pr"`
-	}
-	decode(t, resp, &prResp)
-	require.GreaterOrEqual(t, len(prResp.PR.Reviewers), 1)
-
-	oldReviewer := prResp.PR.Reviewers[0]
+	require.NoError(t, err)
 
-	resp = post(t, "/pullRequest/reassign", map[string]string{
-		"pull_request_id": "test_pr1",
-		"old_user_id":     oldReviewer,
+	pr, err := c.CreatePR(ctx, client.CreatePRRequest{
+		PullRequestID:   "test_pr1",
+		PullRequestName: "New login",
+		AuthorID:        "u1",
 	})
-	require.Equal(t, 200, resp.StatusCode)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(pr.AssignedReviewers), 1)
 
-	var reassigned struct {
-		Replaced string `json:"replaced_by"`
-	}
-	decode(t, resp, &reassigned)
-	require.NotEqual(t, oldReviewer, reassigned.Replaced)
+	oldReviewer := pr.AssignedReviewers[0]
 
-	resp = get(t, "/users/getReview?user_id="+reassigned.Replaced)
-	require.Equal(t, 200, resp.StatusCode)
+	reassigned, err := c.ReassignReviewer(ctx, "test_pr1", oldReviewer)
+	require.NoError(t, err)
+	require.NotEqual(t, oldReviewer, reassigned.ReplacedBy)
 
-	var list struct {
-		PullRequests []interface{} `json:"pull_requests"`
-	}
-	decode(t, resp, &list)
-	require.True(t, len(list.PullRequests) > 0)
+	list, err := c.GetReview(ctx, reassigned.ReplacedBy, client.PRFilter{})
+	require.NoError(t, err)
+	require.True(t, len(list) > 0)
 
-	resp = post(t, "/pullRequest/merge", map[string]string{
-		"pull_request_id": "test_pr1",
-	})
-	require.Equal(t, 200, resp.StatusCode)
+	_, err = c.MergePR(ctx, "test_pr1")
+	require.NoError(t, err)
 
-	resp = post(t, "/pullRequest/reassign", map[string]string{
-		"pull_request_id": "test_pr1",
-		"old_user_id":     reassigned.Replaced,
-	})
-	require.Equal(t, 409, resp.StatusCode)
+	_, err = c.ReassignReviewer(ctx, "test_pr1", reassigned.ReplacedBy)
+	require.ErrorIs(t, err, client.ErrPRMerged)
 }